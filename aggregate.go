@@ -0,0 +1,132 @@
+package fsc
+
+import (
+	"cloud.google.com/go/firestore"
+	pb "cloud.google.com/go/firestore/apiv1/firestorepb"
+	"fmt"
+	"github.com/viant/dsc"
+	"golang.org/x/net/context"
+	"regexp"
+	"strings"
+)
+
+var aggregateExpression = regexp.MustCompile(`(?i)^\s*(count|sum|avg|min|max)\s*\(\s*([^)]*?)\s*\)\s*$`)
+
+var aggregateArgument = regexp.MustCompile(`^[A-Za-z_$][\w$]*(\.[A-Za-z_$][\w$]*)*$`)
+
+//aggregate represents an aggregation function column
+type aggregate struct {
+	function string
+	argument string
+	alias    string
+}
+
+//isServerSide returns true if firestore can compute aggregate, COUNT(column) skips null values thus it is computed on the client side
+func (a *aggregate) isServerSide() bool {
+	switch a.function {
+	case "COUNT":
+		return a.argument == "*" || a.argument == ""
+	case "SUM", "AVG":
+		return true
+	}
+	return false
+}
+
+//isServerSide returns true if firestore can compute all aggregates
+func isServerSide(aggregates []*aggregate) bool {
+	for _, aggregate := range aggregates {
+		if !aggregate.isServerSide() {
+			return false
+		}
+	}
+	return true
+}
+
+//asAggregate returns aggregate for supplied column or nil if column is not an aggregation function, an argument has to be a field path or * for COUNT
+func asAggregate(column *dsc.SQLColumn) (*aggregate, error) {
+	expression := column.Expression
+	if expression == "" {
+		expression = column.Name
+	}
+	matched := aggregateExpression.FindStringSubmatch(expression)
+	if len(matched) == 0 {
+		return nil, nil
+	}
+	var result = &aggregate{
+		function: strings.ToUpper(matched[1]),
+		argument: matched[2],
+		alias:    column.Alias,
+	}
+	isCountAll := result.function == "COUNT" && (result.argument == "*" || result.argument == "")
+	if !isCountAll && !aggregateArgument.MatchString(result.argument) {
+		return nil, fmt.Errorf("unsupported aggregate: %v", expression)
+	}
+	if result.alias == "" {
+		result.alias = column.Name
+	}
+	return result, nil
+}
+
+//asAggregates returns aggregation function columns
+func asAggregates(statement *dsc.QueryStatement) ([]*aggregate, error) {
+	var result = make([]*aggregate, 0)
+	for _, column := range statement.Columns {
+		aggregate, err := asAggregate(column)
+		if err != nil {
+			return nil, err
+		}
+		if aggregate != nil {
+			result = append(result, aggregate)
+		}
+	}
+	return result, nil
+}
+
+//asAggregateValue converts aggregation result value into go value
+func asAggregateValue(value interface{}) interface{} {
+	protoValue, ok := value.(*pb.Value)
+	if !ok {
+		return value
+	}
+	switch actual := protoValue.ValueType.(type) {
+	case *pb.Value_IntegerValue:
+		return actual.IntegerValue
+	case *pb.Value_DoubleValue:
+		return actual.DoubleValue
+	case *pb.Value_NullValue:
+		return nil
+	}
+	return value
+}
+
+//readAggregates runs firestore aggregation query returning a single row
+func (m *manager) readAggregates(ctx context.Context, query firestore.Query, aggregates []*aggregate, scanner *dsc.SQLScanner, readingHandler func(scanner dsc.Scanner) (toContinue bool, err error)) error {
+	aggregationQuery := query.NewAggregationQuery()
+	if transaction := asTransaction(ctx); transaction != nil {
+		aggregationQuery = aggregationQuery.Transaction(transaction.tx)
+	}
+	for i, aggregate := range aggregates {
+		alias := fmt.Sprintf("aggregate_%d", i)
+		switch aggregate.function {
+		case "COUNT":
+			aggregationQuery = aggregationQuery.WithCount(alias)
+		case "SUM":
+			aggregationQuery = aggregationQuery.WithSum(aggregate.argument, alias)
+		case "AVG":
+			aggregationQuery = aggregationQuery.WithAvg(aggregate.argument, alias)
+		default:
+			return fmt.Errorf("unsupported aggregation function: %v", aggregate.function)
+		}
+	}
+	result, err := aggregationQuery.Get(ctx)
+	if err != nil {
+		return err
+	}
+	var record = make(map[string]interface{})
+	for i, aggregate := range aggregates {
+		record[aggregate.alias] = asAggregateValue(result[fmt.Sprintf("aggregate_%d", i)])
+	}
+	scanner.Values = record
+	_, err = readingHandler(scanner)
+	return err
+}
@@ -0,0 +1,44 @@
+package fsc
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"testing"
+)
+
+func TestAsAggregate(t *testing.T) {
+	var useCases = []struct {
+		description string
+		expression  string
+		function    string
+		argument    string
+		isNil       bool
+		hasError    bool
+	}{
+		{description: "count all", expression: "COUNT(*)", function: "COUNT", argument: "*"},
+		{description: "count column", expression: "count(name)", function: "COUNT", argument: "name"},
+		{description: "sum field path", expression: "SUM(address.zip)", function: "SUM", argument: "address.zip"},
+		{description: "not aggregate", expression: "name", isNil: true},
+		{description: "distinct", expression: "COUNT(DISTINCT name)", hasError: true},
+		{description: "arithmetic", expression: "SUM(a + b)", hasError: true},
+		{description: "sum all", expression: "SUM(*)", hasError: true},
+	}
+	for _, useCase := range useCases {
+		aggregate, err := asAggregate(&dsc.SQLColumn{Name: useCase.expression, Expression: useCase.expression})
+		if useCase.hasError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		if useCase.isNil {
+			assert.Nil(t, aggregate, useCase.description)
+			continue
+		}
+		if assert.NotNil(t, aggregate, useCase.description) {
+			assert.Equal(t, useCase.function, aggregate.function, useCase.description)
+			assert.Equal(t, useCase.argument, aggregate.argument, useCase.description)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package fsc
+
+import (
+	"cloud.google.com/go/firestore"
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"golang.org/x/net/context"
+	"strings"
+)
+
+const maxBatchSize = 500
+
+//ChunkError represents failed batch chunk with paths of its documents
+type ChunkError struct {
+	Index int
+	Paths []string
+	Err   error
+}
+
+//Error returns chunk error message
+func (e *ChunkError) Error() string {
+	return fmt.Sprintf("chunk %v failed to write %v, %v", e.Index, strings.Join(e.Paths, ","), e.Err)
+}
+
+//BatchError represents batch persistence error, successfully written chunks are not rolled back
+type BatchError struct {
+	Chunks []*ChunkError
+}
+
+//Error returns batch error message
+func (e *BatchError) Error() string {
+	var messages = make([]string, 0)
+	for _, chunk := range e.Chunks {
+		messages = append(messages, chunk.Error())
+	}
+	return fmt.Sprintf("%v batch chunk(s) failed: %v", len(e.Chunks), strings.Join(messages, "; "))
+}
+
+//batchRecord represents writes of a single record
+type batchRecord struct {
+	writes []*write
+}
+
+//batch represents chunked firestore write batch
+type batch struct {
+	client  *firestore.Client
+	ctx     context.Context
+	size    int
+	records []*batchRecord
+	pending int
+	chunk   int
+	written int
+	err     *BatchError
+}
+
+//add adds record writes, pending chunk is committed when the batch size would be exceeded
+func (b *batch) add(writes []*write) {
+	if b.pending > 0 && b.pending+len(writes) > b.size {
+		b.flush()
+	}
+	b.records = append(b.records, &batchRecord{writes: writes})
+	b.pending += len(writes)
+}
+
+//flush commits pending chunk
+func (b *batch) flush() {
+	if len(b.records) == 0 {
+		return
+	}
+	writeBatch := b.client.Batch()
+	var paths = make([]string, 0)
+	for _, record := range b.records {
+		for _, write := range record.writes {
+			write.addTo(writeBatch)
+			paths = append(paths, relativePath(write.ref.Path))
+		}
+	}
+	if _, err := writeBatch.Commit(b.ctx); err != nil {
+		b.err.Chunks = append(b.err.Chunks, &ChunkError{Index: b.chunk, Paths: paths, Err: asWriteError(strings.Join(paths, ","), err)})
+	} else {
+		b.written += len(b.records)
+	}
+	b.chunk++
+	b.records = make([]*batchRecord, 0)
+	b.pending = 0
+}
+
+//close commits pending chunk, it returns number of written records
+func (b *batch) close() (int, error) {
+	b.flush()
+	if len(b.err.Chunks) > 0 {
+		return b.written, b.err
+	}
+	return b.written, nil
+}
+
+func newBatch(client *firestore.Client, ctx context.Context, size int) *batch {
+	if size <= 0 || size > maxBatchSize {
+		size = maxBatchSize
+	}
+	return &batch{
+		client:  client,
+		ctx:     ctx,
+		size:    size,
+		records: make([]*batchRecord, 0),
+		err:     &BatchError{Chunks: make([]*ChunkError, 0)},
+	}
+}
+
+//asWrites returns document writes for supplied DML
+func (m *manager) asWrites(client *firestore.Client, ctx context.Context, parametrizedSQL *dsc.ParametrizedSQL) ([]*write, error) {
+	statement, err := parseDML(parametrizedSQL.SQL)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = m.tableDescriptor(client, ctx, statement.Table); err != nil {
+		return nil, err
+	}
+	switch statement.Type {
+	case "INSERT":
+		return m.insertWrites(client, statement, parametrizedSQL.Values)
+	case "UPDATE":
+		return m.updateWrites(client, ctx, statement, parametrizedSQL.Values)
+	}
+	return nil, fmt.Errorf("unsupported batch statement: %v", statement.Type)
+}
+
+//persistBatch writes records with chunked write batches, all records writes are built before the first chunk is committed, thus an invalid record does not leave earlier records partially written
+func (m *manager) persistBatch(client *firestore.Client, ctx context.Context, records []interface{}, sqlType int, provider dsc.DmlProvider) (int, error) {
+	if len(records) == 0 {
+		return 0, nil
+	}
+	var recordWrites = make([][]*write, 0)
+	for _, record := range records {
+		parametrizedSQL := provider.Get(sqlType, record)
+		writes, err := m.asWrites(client, ctx, parametrizedSQL)
+		if err != nil {
+			return 0, err
+		}
+		recordWrites = append(recordWrites, writes)
+	}
+	batch := newBatch(client, ctx, m.config.GetInt(batchSizeKey, maxBatchSize))
+	for i, writes := range recordWrites {
+		setGeneratedKey(records[i], writes)
+		batch.add(writes)
+	}
+	return batch.close()
+}
+
+//persistTransactional buffers records writes in the connection transaction
+func (m *manager) persistTransactional(client *firestore.Client, ctx context.Context, records []interface{}, sqlType int, provider dsc.DmlProvider) (int, error) {
+	for _, record := range records {
+		writes, err := m.asWrites(client, ctx, provider.Get(sqlType, record))
+		if err != nil {
+			return 0, err
+		}
+		setGeneratedKey(record, writes)
+		asTransaction(ctx).add(writes...)
+	}
+	return len(records), nil
+}
+
+//PersistAll persists all records on a connection without a transaction, since firestore transaction is limited to 500 writes, use connection Begin with PersistAllOnConnection for atomic writes
+func (m *manager) PersistAll(dataPointer interface{}, table string, provider dsc.DmlProvider) (inserted int, updated int, err error) {
+	connection, err := m.ConnectionProvider().Get()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer connection.Close()
+	return m.PersistAllOnConnection(connection, dataPointer, table, provider)
+}
+
+//DeleteAll deletes all records on a connection without a transaction, use connection Begin with DeleteAllOnConnection for atomic deletes
+func (m *manager) DeleteAll(dataPointer interface{}, table string, keyProvider dsc.KeyGetter) (deleted int, err error) {
+	connection, err := m.ConnectionProvider().Get()
+	if err != nil {
+		return 0, err
+	}
+	defer connection.Close()
+	return m.DeleteAllOnConnection(connection, dataPointer, table, keyProvider)
+}
+
+//PersistAllOnConnection persists all records with firestore write batches, bulk writer in bulk write mode, writes are buffered in a connection transaction
+func (m *manager) PersistAllOnConnection(connection dsc.Connection, dataPointer interface{}, table string, provider dsc.DmlProvider) (inserted int, updated int, err error) {
+	dialect := dsc.GetDatastoreDialect(m.config.DriverName)
+	if !dialect.CanPersistBatch() && !m.config.bulkWrite {
+		return m.AbstractManager.PersistAllOnConnection(connection, dataPointer, table, provider)
+	}
+	if provider, err = dsc.NewDmlProviderIfNeeded(provider, table, toolbox.DiscoverComponentType(dataPointer)); err != nil {
+		return 0, 0, err
+	}
+	var generated = make([]interface{}, 0)
+	if m.isAutoID(table) {
+		dataPointer, generated = withoutKeys(dataPointer, provider)
+	}
+	insertables, updatables, err := m.ClassifyDataAsInsertableOrUpdatable(connection, dataPointer, table, provider)
+	if err != nil {
+		return 0, 0, err
+	}
+	insertables = append(insertables, generated...)
+	client, ctx, err := asClient(connection)
+	if err != nil {
+		return 0, 0, err
+	}
+	var persist = m.persistBatch
+	if asTransaction(ctx) != nil {
+		persist = m.persistTransactional
+	} else if m.config.bulkWrite {
+		persist = m.persistBulk
+	}
+	if inserted, err = persist(client, ctx, insertables, dsc.SQLTypeInsert, provider); err != nil {
+		return inserted, 0, err
+	}
+	updated, err = persist(client, ctx, updatables, dsc.SQLTypeUpdate, provider)
+	return inserted, updated, err
+}
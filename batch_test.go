@@ -0,0 +1,121 @@
+package fsc
+
+import (
+	"cloud.google.com/go/firestore"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"os"
+	"testing"
+	"time"
+)
+
+//newUnreachableClient returns client of not running emulator, all its writes fail
+func newUnreachableClient(t *testing.T) (*firestore.Client, context.Context, func()) {
+	previous := os.Getenv("FIRESTORE_EMULATOR_HOST")
+	os.Setenv("FIRESTORE_EMULATOR_HOST", "127.0.0.1:1")
+	client, err := firestore.NewClient(context.Background(), "test")
+	os.Setenv("FIRESTORE_EMULATOR_HOST", previous)
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	return client, ctx, func() {
+		cancel()
+		client.Close()
+	}
+}
+
+func TestBatch_Chunks(t *testing.T) {
+	client, ctx, closer := newUnreachableClient(t)
+	defer closer()
+	var useCases = []struct {
+		description string
+		size        int
+		records     int
+		writes      int
+		expected    []int
+	}{
+		{
+			description: "default size",
+			size:        0,
+			records:     1201,
+			writes:      1,
+			expected:    []int{500, 500, 201},
+		},
+		{
+			description: "custom size",
+			size:        100,
+			records:     250,
+			writes:      1,
+			expected:    []int{100, 100, 50},
+		},
+		{
+			description: "record writes are not split",
+			size:        0,
+			records:     3,
+			writes:      200,
+			expected:    []int{400, 200},
+		},
+	}
+
+	for _, useCase := range useCases {
+		batch := newBatch(client, ctx, useCase.size)
+		for i := 0; i < useCase.records; i++ {
+			var writes = make([]*write, 0)
+			for j := 0; j < useCase.writes; j++ {
+				ref := client.Collection("users").Doc(fmt.Sprintf("%v_%v", i, j))
+				writes = append(writes, &write{ref: ref, record: map[string]interface{}{"id": i}})
+			}
+			batch.add(writes)
+		}
+		written, err := batch.close()
+		assert.Equal(t, 0, written, useCase.description)
+		batchError, ok := err.(*BatchError)
+		if !assert.True(t, ok, useCase.description) {
+			continue
+		}
+		if !assert.Equal(t, len(useCase.expected), len(batchError.Chunks), useCase.description) {
+			continue
+		}
+		for i, chunk := range batchError.Chunks {
+			assert.Equal(t, i, chunk.Index, useCase.description)
+			assert.Equal(t, useCase.expected[i], len(chunk.Paths), useCase.description)
+			assert.NotNil(t, chunk.Err, useCase.description)
+		}
+	}
+}
+
+func TestManager_WriteBulk(t *testing.T) {
+	client, ctx, closer := newUnreachableClient(t)
+	defer closer()
+	var records = [][]*write{
+		{
+			{ref: client.Collection("users").Doc("1"), record: map[string]interface{}{"id": 1}},
+		},
+		{
+			{ref: client.Collection("users").Doc("2"), record: map[string]interface{}{"id": 2}},
+			{ref: client.Collection("users/2/orders").Doc("1"), record: map[string]interface{}{"id": 1}},
+		},
+	}
+	manager := &manager{}
+	written, err := manager.writeBulk(client, ctx, records)
+	assert.Equal(t, 0, written)
+	bulkError, ok := err.(*BulkError)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, 0, bulkError.Written)
+	assert.Equal(t, 3, len(bulkError.Failures))
+	for _, path := range []string{"users/1", "users/2", "users/2/orders/1"} {
+		assert.NotNil(t, bulkError.Failures[path], path)
+	}
+	assert.Contains(t, bulkError.Error(), "failed to write 3 document(s)")
+}
+
+func TestBatchError_Error(t *testing.T) {
+	err := &BatchError{Chunks: []*ChunkError{
+		{Index: 1, Paths: []string{"users/1", "users/2"}, Err: fmt.Errorf("unavailable")},
+	}}
+	assert.Equal(t, "1 batch chunk(s) failed: chunk 1 failed to write users/1,users/2, unavailable", err.Error())
+}
@@ -0,0 +1,122 @@
+package fsc
+
+import (
+	"cloud.google.com/go/firestore"
+	"fmt"
+	"github.com/viant/dsc"
+	"golang.org/x/net/context"
+	"sort"
+	"strings"
+)
+
+//BulkError represents bulk write report with per document failures
+type BulkError struct {
+	Written  int
+	Failures map[string]error
+}
+
+//Error returns bulk error message
+func (e *BulkError) Error() string {
+	var paths = make([]string, 0)
+	for path := range e.Failures {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	var messages = make([]string, 0)
+	for _, path := range paths {
+		messages = append(messages, fmt.Sprintf("%v: %v", path, e.Failures[path]))
+	}
+	return fmt.Sprintf("failed to write %v document(s), written %v record(s): %v", len(e.Failures), e.Written, strings.Join(messages, "; "))
+}
+
+//bulkJob represents enqueued write
+type bulkJob struct {
+	record int
+	path   string
+	job    *firestore.BulkWriterJob
+}
+
+//asBulkRecords returns each write as a separate record
+func asBulkRecords(writes []*write) [][]*write {
+	var result = make([][]*write, 0)
+	for _, item := range writes {
+		result = append(result, []*write{item})
+	}
+	return result
+}
+
+//bulk represents firestore bulk writer, writes are flushed by the writer while records are added
+type bulk struct {
+	writer  *firestore.BulkWriter
+	records int
+	failed  map[int]bool
+	jobs    []*bulkJob
+	report  *BulkError
+}
+
+//add enqueues record writes
+func (b *bulk) add(writes []*write) {
+	record := b.records
+	b.records++
+	for _, write := range writes {
+		path := relativePath(write.ref.Path)
+		job, err := write.enqueue(b.writer)
+		if err != nil {
+			b.report.Failures[path] = err
+			b.failed[record] = true
+			continue
+		}
+		b.jobs = append(b.jobs, &bulkJob{record: record, path: path, job: job})
+	}
+}
+
+//close waits for enqueued writes, it returns number of records with all writes succeeded, failures do not stop other writes
+func (b *bulk) close() (int, error) {
+	b.writer.End()
+	for _, job := range b.jobs {
+		if _, err := job.job.Results(); err != nil {
+			b.report.Failures[job.path] = asWriteError(job.path, err)
+			b.failed[job.record] = true
+		}
+	}
+	b.report.Written = b.records - len(b.failed)
+	if len(b.report.Failures) > 0 {
+		return b.report.Written, b.report
+	}
+	return b.report.Written, nil
+}
+
+func newBulk(client *firestore.Client, ctx context.Context) *bulk {
+	return &bulk{
+		writer: client.BulkWriter(ctx),
+		failed: make(map[int]bool),
+		jobs:   make([]*bulkJob, 0),
+		report: &BulkError{Failures: make(map[string]error)},
+	}
+}
+
+//writeBulk writes records with firestore bulk writer, it returns number of records with all writes succeeded
+func (m *manager) writeBulk(client *firestore.Client, ctx context.Context, records [][]*write) (int, error) {
+	bulk := newBulk(client, ctx)
+	for _, writes := range records {
+		bulk.add(writes)
+	}
+	return bulk.close()
+}
+
+//persistBulk writes records with firestore bulk writer
+func (m *manager) persistBulk(client *firestore.Client, ctx context.Context, records []interface{}, sqlType int, provider dsc.DmlProvider) (int, error) {
+	if len(records) == 0 {
+		return 0, nil
+	}
+	var bulkRecords = make([][]*write, 0)
+	for _, record := range records {
+		writes, err := m.asWrites(client, ctx, provider.Get(sqlType, record))
+		if err != nil {
+			return 0, err
+		}
+		setGeneratedKey(record, writes)
+		bulkRecords = append(bulkRecords, writes)
+	}
+	return m.writeBulk(client, ctx, bulkRecords)
+}
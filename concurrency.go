@@ -0,0 +1,134 @@
+package fsc
+
+import (
+	"cloud.google.com/go/firestore"
+	"fmt"
+	"github.com/viant/toolbox"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"time"
+)
+
+//ConflictError represents optimistic concurrency conflict, the document was modified or deleted since it was read
+type ConflictError struct {
+	Path string
+}
+
+//Error returns conflict error message
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("conflict: %v was modified since last read", e.Path)
+}
+
+//IsConflict returns true if error or any of batch, bulk errors is a conflict error
+func IsConflict(err error) bool {
+	switch actual := err.(type) {
+	case *ConflictError:
+		return true
+	case *ChunkError:
+		return IsConflict(actual.Err)
+	case *BatchError:
+		for _, chunk := range actual.Chunks {
+			if IsConflict(chunk) {
+				return true
+			}
+		}
+	case *BulkError:
+		for _, failure := range actual.Failures {
+			if IsConflict(failure) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//asWriteError returns conflict error for failed precondition, duplicate key error for already existing document
+func asWriteError(path string, err error) error {
+	if err == nil {
+		return nil
+	}
+	switch grpc.Code(err) {
+	case codes.FailedPrecondition:
+		return &ConflictError{Path: path}
+	case codes.AlreadyExists:
+		return &DuplicateKeyError{Path: path}
+	}
+	return err
+}
+
+//asUpdateTime returns update time column value
+func asUpdateTime(value interface{}) (time.Time, error) {
+	switch actual := value.(type) {
+	case time.Time:
+		return actual, nil
+	case *time.Time:
+		if actual == nil {
+			return time.Time{}, nil
+		}
+		return *actual, nil
+	}
+	text := toolbox.AsString(value)
+	if text == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339Nano, text)
+}
+
+func (m *manager) getUpdateTimeColumn(table string) string {
+	return m.tableString(table, updateTimeColumnKey)
+}
+
+func (m *manager) getVersionColumn(table string) string {
+	return m.tableString(table, versionColumnKey)
+}
+
+//preconditions returns update preconditions for the record carrying last seen update time or version, concurrency columns are removed or incremented in the record
+func (m *manager) preconditions(ctx context.Context, table string, ref *firestore.DocumentRef, record map[string]interface{}) ([]firestore.Precondition, error) {
+	var result = make([]firestore.Precondition, 0)
+	if column := m.getUpdateTimeColumn(table); column != "" {
+		if value, ok := record[column]; ok {
+			delete(record, column)
+			updateTime, err := asUpdateTime(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %v value: %v, %v", column, value, err)
+			}
+			if !updateTime.IsZero() {
+				result = append(result, firestore.LastUpdateTime(updateTime))
+			}
+		}
+	}
+	column := m.getVersionColumn(table)
+	expected, ok := record[column]
+	if column == "" || !ok || expected == nil {
+		return result, nil
+	}
+	snapshot, err := getDocument(ctx, ref)
+	if err != nil {
+		if grpc.Code(err) == codes.NotFound {
+			return nil, &ConflictError{Path: relativePath(ref.Path)}
+		}
+		return nil, err
+	}
+	actual, _ := snapshot.DataAt(column)
+	if compareValues(actual, expected) != 0 {
+		return nil, &ConflictError{Path: relativePath(ref.Path)}
+	}
+	record[column] = toolbox.AsInt(expected) + 1
+	if len(result) == 0 {
+		result = append(result, firestore.LastUpdateTime(snapshot.UpdateTime))
+	}
+	return result, nil
+}
+
+//initVersion removes update time column and sets initial version of the inserted record
+func (m *manager) initVersion(table string, record map[string]interface{}) {
+	if column := m.getUpdateTimeColumn(table); column != "" {
+		delete(record, column)
+	}
+	if column := m.getVersionColumn(table); column != "" {
+		if value, ok := record[column]; !ok || toolbox.AsInt(value) == 0 {
+			record[column] = 1
+		}
+	}
+}
@@ -34,21 +34,70 @@ func asClient(connection dsc.Connection) (*firestore.Client, context.Context, er
 
 type connection struct {
 	*dsc.AbstractConnection
-	client    *firestore.Client
-	ctx       *context.Context
-	cancelCtx context.CancelFunc
-	dbName    string
+	client      *firestore.Client
+	ctx         *context.Context
+	cancelCtx   context.CancelFunc
+	dbName      string
+	transaction *transaction
+	txCtx       context.Context
+}
+
+//Begin starts firestore transaction, reads on the connection run in the transaction, writes are buffered till commit
+func (c *connection) Begin() error {
+	if c.transaction != nil {
+		return errors.New("transaction has been already started")
+	}
+	transaction := newTransaction()
+	if err := transaction.begin(c.client, *c.ctx); err != nil {
+		return err
+	}
+	c.transaction = transaction
+	c.txCtx = withTransaction(*c.ctx, transaction)
+	return nil
+}
+
+//Commit commits buffered writes
+func (c *connection) Commit() error {
+	return c.endTransaction(true)
+}
+
+//Rollback discards buffered writes
+func (c *connection) Rollback() error {
+	return c.endTransaction(false)
+}
+
+func (c *connection) endTransaction(commit bool) error {
+	if c.transaction == nil {
+		return nil
+	}
+	transaction := c.transaction
+	c.transaction = nil
+	c.txCtx = nil
+	return transaction.end(commit)
+}
+
+//Close rolls back pending transaction before connection is returned to the pool
+func (c *connection) Close() error {
+	err := c.endTransaction(false)
+	if closeErr := c.AbstractConnection.Close(); err == nil {
+		err = closeErr
+	}
+	return err
 }
 
 func (c *connection) CloseNow() error {
+	err := c.endTransaction(false)
 	c.cancelCtx()
-	return nil
+	return err
 }
 
 func (c *connection) Unwrap(targetType interface{}) interface{} {
 	if targetType == ClientPointerKey {
 		return c.client
 	} else if targetType == ContextPointerKey {
+		if c.transaction != nil {
+			return &c.txCtx
+		}
 		return c.ctx
 	}
 	panic(fmt.Sprintf("unsupported targetType type %v", targetType))
@@ -1,60 +1,328 @@
 package fsc
 
 import (
+	"cloud.google.com/go/firestore"
 	"fmt"
 	"github.com/viant/dsc"
 	"github.com/viant/toolbox"
+	"strconv"
 	"strings"
 )
 
-//asCriteriaMap returns criteria  map
-func asCriteriaMap(sqlCriteria *dsc.SQLCriteria, paramIterator toolbox.Iterator) (map[string]interface{}, error) {
-	var result = make(map[string]interface{})
-	if len(sqlCriteria.Criteria) == 0 {
-		return result, nil
+//operators maps SQL operators to firestore where clause operators
+var operators = map[string]string{
+	"=":      "==",
+	"!=":     "!=",
+	"<>":     "!=",
+	"<":      "<",
+	"<=":     "<=",
+	">":      ">",
+	">=":     ">=",
+	"IN":     "in",
+	"NOT IN": "not-in",
+}
+
+//criterion represents a single firestore where clause
+type criterion struct {
+	column   string
+	operator string
+	value    interface{}
+}
+
+//isKeyLookup returns true if criterion can be resolved with direct document lookup
+func (c *criterion) isKeyLookup(keyColumn string) bool {
+	return c.column == keyColumn && (c.operator == "==" || c.operator == "in")
+}
+
+//asOperator returns firestore operator for supplied SQL criterion
+func asOperator(criterion *dsc.SQLCriterion) (string, error) {
+	operator := strings.ToUpper(strings.Join(strings.Fields(criterion.Operator), " "))
+	if criterion.Inverse {
+		operator = "NOT " + operator
 	}
-	var value interface{}
+	result, ok := operators[operator]
+	if !ok {
+		return "", fmt.Errorf("unsuppored operator: %v", criterion.Operator)
+	}
+	return result, nil
+}
+
+//asLiteral converts SQL literal into corresponding go value
+func asLiteral(literal string) interface{} {
+	literal = strings.TrimSpace(literal)
+	if strings.HasPrefix(literal, "'") || strings.HasPrefix(literal, "\"") {
+		return strings.Trim(literal, "'\"")
+	}
+	switch strings.ToLower(literal) {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+	if intValue, err := strconv.ParseInt(literal, 10, 64); err == nil {
+		return intValue
+	}
+	if floatValue, err := strconv.ParseFloat(literal, 64); err == nil {
+		return floatValue
+	}
+	return literal
+}
 
-	if len(sqlCriteria.Criteria) == 1 {
-		criteria := sqlCriteria.Criteria[0]
-		if !(criteria.Operator == "=" || criteria.Operator == "IN") {
-			return nil, fmt.Errorf("unsuppored operator: %v", criteria.Operator)
-		}
-		column, ok := criteria.LeftOperand.(string)
-		columnValue := criteria.RightOperand.(string)
-		if !ok || column == "?" {
-			column, ok = criteria.RightOperand.(string)
-		}
-		bindParamCount := strings.Count(columnValue, "?")
-		switch bindParamCount {
-		case 0:
-			result[column] = strings.Trim(columnValue, "'")
-		case 1:
+//asCriterion returns firestore criterion for supplied SQL criterion, bind parameters are consumed from paramIterator
+func asCriterion(sqlCriterion *dsc.SQLCriterion, paramIterator toolbox.Iterator) (*criterion, error) {
+	operator, err := asOperator(sqlCriterion)
+	if err != nil {
+		return nil, err
+	}
+	column, ok := sqlCriterion.LeftOperand.(string)
+	columnValue := toolbox.AsString(sqlCriterion.RightOperand)
+	if !ok || column == "?" {
+		column, ok = sqlCriterion.RightOperand.(string)
+		columnValue = toolbox.AsString(sqlCriterion.LeftOperand)
+	}
+	var result = &criterion{column: column, operator: operator}
+	var isSlice = operator == "in" || operator == "not-in"
+	bindParamCount := strings.Count(columnValue, "?")
+	var value interface{}
+	switch bindParamCount {
+	case 0:
+		columnValue = strings.Trim(strings.TrimSpace(columnValue), "()")
+		if !isSlice {
+			result.value = asLiteral(columnValue)
+			break
+		}
+		var values = make([]interface{}, 0)
+		for _, item := range strings.Split(columnValue, ",") {
+			values = append(values, asLiteral(item))
+		}
+		result.value = values
+	case 1:
+		if !paramIterator.HasNext() {
+			return nil, fmt.Errorf("missing bind param: %v %v %v", sqlCriterion.LeftOperand, sqlCriterion.Operator, sqlCriterion.RightOperand)
+		}
+		if err := paramIterator.Next(&value); err != nil {
+			return nil, err
+		}
+		result.value = value
+	default:
+		var values = make([]interface{}, 0)
+		for i := 0; i < bindParamCount; i++ {
 			if !paramIterator.HasNext() {
-				return nil, fmt.Errorf("missing bind param: %v %v %v", criteria.LeftOperand, criteria.Operator, criteria.RightOperand)
+				return nil, fmt.Errorf("missing bind param: %v %v %v", sqlCriterion.LeftOperand, sqlCriterion.Operator, sqlCriterion.RightOperand)
 			}
 			if err := paramIterator.Next(&value); err != nil {
 				return nil, err
 			}
-			result[column] = value
-			break
-		default:
-			var values = make([]interface{}, 0)
-			for i := 0; i < bindParamCount; i++ {
-				if !paramIterator.HasNext() {
-					return nil, fmt.Errorf("missing bind param: %v %v %v", criteria.LeftOperand, criteria.Operator, criteria.RightOperand)
-				}
-
-				if err := paramIterator.Next(&value); err != nil {
-					return nil, err
-				}
-				values = append(values, value)
+			values = append(values, value)
+		}
+		result.value = values
+	}
+	return result, nil
+}
+
+//asCriteria returns firestore criteria for supplied SQL criteria, criteria are joined with AND, bind parameters are consumed in order
+func asCriteria(sqlCriteria *dsc.SQLCriteria, paramIterator toolbox.Iterator) ([]*criterion, error) {
+	predicate, err := asPredicate(sqlCriteria, paramIterator)
+	if err != nil {
+		return nil, err
+	}
+	if !predicate.isConjunction() {
+		return nil, fmt.Errorf("unsupported logical operator: OR")
+	}
+	return predicate.criteria(), nil
+}
+
+//asCriteriaMap returns criteria  map
+func asCriteriaMap(sqlCriteria *dsc.SQLCriteria, paramIterator toolbox.Iterator) (map[string]interface{}, error) {
+	var result = make(map[string]interface{})
+	criteria, err := asCriteria(sqlCriteria, paramIterator)
+	if err != nil {
+		return nil, err
+	}
+	for _, criterion := range criteria {
+		if !(criterion.operator == "==" || criterion.operator == "in") {
+			return nil, fmt.Errorf("unsuppored operator: %v", criterion.operator)
+		}
+		result[criterion.column] = criterion.value
+	}
+	return result, nil
+}
+
+//whereValue returns criterion value compatible with firestore where clause
+func (c *criterion) whereValue() interface{} {
+	if c.operator != "in" && c.operator != "not-in" {
+		return c.value
+	}
+	if toolbox.IsSlice(c.value) {
+		return toolbox.AsSlice(c.value)
+	}
+	return []interface{}{c.value}
+}
+
+//predicate represents boolean criteria tree, leaf predicate holds criterion, group predicate joins its predicates with AND or OR
+type predicate struct {
+	criterion  *criterion
+	operator   string
+	predicates []*predicate
+}
+
+//isConjunction returns true if predicate uses only AND logical operator
+func (p *predicate) isConjunction() bool {
+	if p.criterion != nil {
+		return true
+	}
+	if len(p.predicates) > 1 && p.operator != "AND" {
+		return false
+	}
+	for _, item := range p.predicates {
+		if !item.isConjunction() {
+			return false
+		}
+	}
+	return true
+}
+
+//filter returns firestore entity filter
+func (p *predicate) filter() firestore.EntityFilter {
+	if p.criterion != nil {
+		return firestore.PropertyFilter{Path: p.criterion.column, Operator: p.criterion.operator, Value: p.criterion.whereValue()}
+	}
+	if len(p.predicates) == 1 {
+		return p.predicates[0].filter()
+	}
+	var filters = make([]firestore.EntityFilter, 0)
+	for _, item := range p.predicates {
+		filters = append(filters, item.filter())
+	}
+	if p.operator == "OR" {
+		return firestore.OrFilter{Filters: filters}
+	}
+	return firestore.AndFilter{Filters: filters}
+}
+
+//apply returns query filtered with the predicate, conjunction uses chained where clauses
+func (p *predicate) apply(query firestore.Query) firestore.Query {
+	if !p.isConjunction() {
+		return query.WhereEntity(p.filter())
+	}
+	for _, criterion := range p.criteria() {
+		query = query.Where(criterion.column, criterion.operator, criterion.whereValue())
+	}
+	return query
+}
+
+//bind removes top level equality criteria on supplied columns, it returns removed criteria values
+func (p *predicate) bind(columns []string) map[string]interface{} {
+	var result = make(map[string]interface{})
+	if len(columns) == 0 || p.criterion != nil || !(p.operator == "AND" || len(p.predicates) == 1) {
+		return result
+	}
+	var bindable = make(map[string]bool)
+	for _, column := range columns {
+		bindable[column] = true
+	}
+	var predicates = make([]*predicate, 0)
+	for _, item := range p.predicates {
+		if item.criterion != nil && item.criterion.operator == "==" && bindable[item.criterion.column] {
+			result[item.criterion.column] = item.criterion.value
+			continue
+		}
+		predicates = append(predicates, item)
+	}
+	p.predicates = predicates
+	return result
+}
+
+//keyValue returns key column value if predicate joins with AND key lookup criterion and equality criteria on path columns only
+func (p *predicate) keyValue(keyColumn string, pathColumns []string) (interface{}, bool) {
+	if !p.isConjunction() {
+		return nil, false
+	}
+	var bindable = make(map[string]bool)
+	for _, column := range pathColumns {
+		bindable[column] = true
+	}
+	var result interface{}
+	var found = false
+	for _, criterion := range p.criteria() {
+		switch {
+		case criterion.isKeyLookup(keyColumn):
+			result, found = criterion.value, true
+		case criterion.operator != "==" || !bindable[criterion.column]:
+			return nil, false
+		}
+	}
+	return result, found
+}
+
+//disjunctions returns predicate in disjunctive normal form, each element represents criteria joined with AND
+func (p *predicate) disjunctions() [][]*criterion {
+	if p.criterion != nil {
+		return [][]*criterion{{p.criterion}}
+	}
+	if p.operator == "OR" {
+		var result = make([][]*criterion, 0)
+		for _, item := range p.predicates {
+			result = append(result, item.disjunctions()...)
+		}
+		return result
+	}
+	var result = [][]*criterion{{}}
+	for _, item := range p.predicates {
+		var expanded = make([][]*criterion, 0)
+		for _, left := range result {
+			for _, right := range item.disjunctions() {
+				var conjunction = append(append([]*criterion{}, left...), right...)
+				expanded = append(expanded, conjunction)
 			}
-			result[column] = values
 		}
+		result = expanded
+	}
+	return result
+}
 
-	} else {
-		return nil, fmt.Errorf("criteria on one key is supported")
+//criteria returns all leaf criteria
+func (p *predicate) criteria() []*criterion {
+	if p.criterion != nil {
+		return []*criterion{p.criterion}
+	}
+	var result = make([]*criterion, 0)
+	for _, item := range p.predicates {
+		result = append(result, item.criteria()...)
+	}
+	return result
+}
+
+//asPredicate returns boolean criteria tree for supplied SQL criteria, bind parameters are consumed in order
+func asPredicate(sqlCriteria *dsc.SQLCriteria, paramIterator toolbox.Iterator) (*predicate, error) {
+	var result = &predicate{operator: "AND", predicates: make([]*predicate, 0)}
+	if sqlCriteria == nil {
+		return result, nil
+	}
+	if sqlCriteria.LogicalOperator != "" {
+		result.operator = strings.ToUpper(sqlCriteria.LogicalOperator)
+	}
+	if result.operator != "AND" && result.operator != "OR" {
+		return nil, fmt.Errorf("unsupported logical operator: %v", sqlCriteria.LogicalOperator)
+	}
+	for _, sqlCriterion := range sqlCriteria.Criteria {
+		if sqlCriterion.Criteria != nil {
+			group, err := asPredicate(sqlCriterion.Criteria, paramIterator)
+			if err != nil {
+				return nil, err
+			}
+			if len(group.predicates) > 0 {
+				result.predicates = append(result.predicates, group)
+			}
+			continue
+		}
+		criterion, err := asCriterion(sqlCriterion, paramIterator)
+		if err != nil {
+			return nil, err
+		}
+		result.predicates = append(result.predicates, &predicate{criterion: criterion})
 	}
 	return result, nil
 }
@@ -0,0 +1,131 @@
+package fsc
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newCriterion(column, operator string, value interface{}) *predicate {
+	return &predicate{criterion: &criterion{column: column, operator: operator, value: value}}
+}
+
+func TestPredicate_Bind(t *testing.T) {
+	var useCases = []struct {
+		description string
+		predicate   *predicate
+		columns     []string
+		expected    map[string]interface{}
+		remaining   int
+	}{
+		{
+			description: "bind equality criterion",
+			predicate:   &predicate{operator: "AND", predicates: []*predicate{newCriterion("userId", "==", 1), newCriterion("amount", ">", 5)}},
+			columns:     []string{"userId"},
+			expected:    map[string]interface{}{"userId": 1},
+			remaining:   1,
+		},
+		{
+			description: "non equality criterion is not bound",
+			predicate:   &predicate{operator: "AND", predicates: []*predicate{newCriterion("userId", "in", []interface{}{1, 2})}},
+			columns:     []string{"userId"},
+			expected:    map[string]interface{}{},
+			remaining:   1,
+		},
+		{
+			description: "disjunction is not bound",
+			predicate:   &predicate{operator: "OR", predicates: []*predicate{newCriterion("userId", "==", 1), newCriterion("amount", ">", 5)}},
+			columns:     []string{"userId"},
+			expected:    map[string]interface{}{},
+			remaining:   2,
+		},
+		{
+			description: "no path columns",
+			predicate:   &predicate{operator: "AND", predicates: []*predicate{newCriterion("userId", "==", 1)}},
+			expected:    map[string]interface{}{},
+			remaining:   1,
+		},
+	}
+	for _, useCase := range useCases {
+		assert.EqualValues(t, useCase.expected, useCase.predicate.bind(useCase.columns), useCase.description)
+		assert.Equal(t, useCase.remaining, len(useCase.predicate.predicates), useCase.description)
+	}
+}
+
+func TestPredicate_KeyValue(t *testing.T) {
+	var useCases = []struct {
+		description string
+		predicate   *predicate
+		expected    interface{}
+		found       bool
+	}{
+		{
+			description: "key equality",
+			predicate:   &predicate{operator: "AND", predicates: []*predicate{newCriterion("id", "==", 1)}},
+			expected:    1,
+			found:       true,
+		},
+		{
+			description: "key with path column",
+			predicate:   &predicate{operator: "AND", predicates: []*predicate{newCriterion("userId", "==", 2), newCriterion("id", "in", []interface{}{1, 3})}},
+			expected:    []interface{}{1, 3},
+			found:       true,
+		},
+		{
+			description: "key with non path column",
+			predicate:   &predicate{operator: "AND", predicates: []*predicate{newCriterion("id", "==", 1), newCriterion("name", "==", "x")}},
+		},
+		{
+			description: "key range",
+			predicate:   &predicate{operator: "AND", predicates: []*predicate{newCriterion("id", ">", 1)}},
+		},
+		{
+			description: "key disjunction",
+			predicate:   &predicate{operator: "OR", predicates: []*predicate{newCriterion("id", "==", 1), newCriterion("id", "==", 2)}},
+		},
+		{
+			description: "no criteria",
+			predicate:   &predicate{operator: "AND"},
+		},
+	}
+	for _, useCase := range useCases {
+		value, found := useCase.predicate.keyValue("id", []string{"userId"})
+		assert.Equal(t, useCase.found, found, useCase.description)
+		assert.EqualValues(t, useCase.expected, value, useCase.description)
+	}
+}
+
+func TestPredicate_Disjunctions(t *testing.T) {
+	a, b, c := newCriterion("a", "==", 1), newCriterion("b", "==", 2), newCriterion("c", "==", 3)
+	var useCases = []struct {
+		description string
+		predicate   *predicate
+		expected    [][]string
+	}{
+		{
+			description: "conjunction",
+			predicate:   &predicate{operator: "AND", predicates: []*predicate{a, b}},
+			expected:    [][]string{{"a", "b"}},
+		},
+		{
+			description: "disjunction",
+			predicate:   &predicate{operator: "OR", predicates: []*predicate{a, b}},
+			expected:    [][]string{{"a"}, {"b"}},
+		},
+		{
+			description: "distributed conjunction",
+			predicate:   &predicate{operator: "AND", predicates: []*predicate{{operator: "OR", predicates: []*predicate{a, b}}, c}},
+			expected:    [][]string{{"a", "c"}, {"b", "c"}},
+		},
+	}
+	for _, useCase := range useCases {
+		var actual = make([][]string, 0)
+		for _, conjunction := range useCase.predicate.disjunctions() {
+			var columns = make([]string, 0)
+			for _, criterion := range conjunction {
+				columns = append(columns, criterion.column)
+			}
+			actual = append(actual, columns)
+		}
+		assert.EqualValues(t, useCase.expected, actual, useCase.description)
+	}
+}
@@ -0,0 +1,170 @@
+package fsc
+
+import (
+	"bytes"
+	"cloud.google.com/go/firestore"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/viant/dsc"
+	"time"
+)
+
+//Cursor represents read position, when passed as SQL parameter reading continues from the cursor position and the cursor advances to the last read document
+type Cursor struct {
+	reverse bool
+	values  []interface{}
+	path    string
+	ref     *firestore.DocumentRef
+}
+
+type cursorValue struct {
+	Type  string
+	Value interface{}
+}
+
+type cursorToken struct {
+	Path   string
+	Values []*cursorValue
+}
+
+//Token returns opaque continuation token, empty token represents beginning of the collection
+func (c *Cursor) Token() string {
+	if c.path == "" {
+		return ""
+	}
+	var token = &cursorToken{Path: c.path, Values: make([]*cursorValue, 0)}
+	for _, value := range c.values {
+		token.Values = append(token.Values, asCursorValue(value))
+	}
+	encoded, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+//bind resolves document reference for the cursor position
+func (c *Cursor) bind(client *firestore.Client) {
+	if c.path != "" {
+		c.ref = client.Doc(c.path)
+	}
+}
+
+//apply applies cursor position to supplied ordered query, document ID is added as the last order column to make the position unique
+func (c *Cursor) apply(query firestore.Query, orderBy []*dsc.SQLColumn) firestore.Query {
+	var direction = firestore.Asc
+	if len(orderBy) > 0 && orderBy[len(orderBy)-1].IsDesc {
+		direction = firestore.Desc
+	}
+	query = query.OrderBy(firestore.DocumentID, direction)
+	if c.ref == nil {
+		return query
+	}
+	var values = append(append([]interface{}{}, c.values...), c.ref)
+	if c.reverse {
+		return query.EndBefore(values...)
+	}
+	return query.StartAfter(values...)
+}
+
+//track returns handler moving the cursor to the read documents
+func (c *Cursor) track(orderBy []*dsc.SQLColumn, handler documentHandler) documentHandler {
+	var moved = false
+	return func(document *firestore.DocumentSnapshot) (bool, error) {
+		if !c.reverse || !moved {
+			moved = true
+			c.path = relativePath(document.Ref.Path)
+			c.ref = document.Ref
+			c.values = make([]interface{}, 0)
+			for _, column := range orderBy {
+				value, _ := document.DataAt(column.Name)
+				c.values = append(c.values, value)
+			}
+		}
+		return handler(document)
+	}
+}
+
+func asCursorValue(value interface{}) *cursorValue {
+	switch actual := value.(type) {
+	case time.Time:
+		return &cursorValue{Type: "time", Value: actual.Format(time.RFC3339Nano)}
+	case []byte:
+		return &cursorValue{Type: "bytes", Value: base64.StdEncoding.EncodeToString(actual)}
+	case int64:
+		return &cursorValue{Type: "int", Value: actual}
+	case float64:
+		return &cursorValue{Type: "float", Value: actual}
+	}
+	return &cursorValue{Value: value}
+}
+
+func fromCursorValue(value *cursorValue) (interface{}, error) {
+	switch value.Type {
+	case "time":
+		return time.Parse(time.RFC3339Nano, fmt.Sprintf("%v", value.Value))
+	case "bytes":
+		return base64.StdEncoding.DecodeString(fmt.Sprintf("%v", value.Value))
+	case "int":
+		number, ok := value.Value.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("invalid int cursor value: %v", value.Value)
+		}
+		return number.Int64()
+	case "float":
+		number, ok := value.Value.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("invalid float cursor value: %v", value.Value)
+		}
+		return number.Float64()
+	}
+	return value.Value, nil
+}
+
+func newCursor(token string, reverse bool) (*Cursor, error) {
+	var result = &Cursor{reverse: reverse, values: make([]interface{}, 0)}
+	if token == "" {
+		return result, nil
+	}
+	encoded, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor token: %v", err)
+	}
+	var decoded = &cursorToken{}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	if err = decoder.Decode(decoded); err != nil {
+		return nil, fmt.Errorf("invalid cursor token: %v", err)
+	}
+	result.path = decoded.Path
+	for _, item := range decoded.Values {
+		value, err := fromCursorValue(item)
+		if err != nil {
+			return nil, err
+		}
+		result.values = append(result.values, value)
+	}
+	return result, nil
+}
+
+//NewCursor creates a cursor reading documents after token position, empty token starts at the beginning of the collection
+func NewCursor(token string) (*Cursor, error) {
+	return newCursor(token, false)
+}
+
+//NewReverseCursor creates a cursor reading documents before token position, the cursor moves to the first read document
+func NewReverseCursor(token string) (*Cursor, error) {
+	return newCursor(token, true)
+}
+
+//asCursor returns SQL parameters without a cursor and the cursor if present
+func asCursor(parameters []interface{}) ([]interface{}, *Cursor) {
+	var result = make([]interface{}, 0)
+	var cursor *Cursor
+	for _, parameter := range parameters {
+		if candidate, ok := parameter.(*Cursor); ok {
+			cursor = candidate
+			continue
+		}
+		result = append(result, parameter)
+	}
+	return result, cursor
+}
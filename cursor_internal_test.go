@@ -0,0 +1,23 @@
+package fsc
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCursor_Token(t *testing.T) {
+	modified := time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC)
+	cursor := &Cursor{
+		path:   "users/1/orders/10",
+		values: []interface{}{"Name 1", int64(3), 2.5, modified, []byte("abc"), true, nil},
+	}
+	decoded, err := NewReverseCursor(cursor.Token())
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.True(t, decoded.reverse)
+	assert.Equal(t, cursor.path, decoded.path)
+	assert.EqualValues(t, cursor.values, decoded.values)
+	assert.Equal(t, cursor.Token(), decoded.Token())
+}
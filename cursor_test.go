@@ -0,0 +1,18 @@
+package fsc_test
+
+import (
+	"github.com/adrianwit/fsc"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewCursor(t *testing.T) {
+	cursor, err := fsc.NewCursor("")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "", cursor.Token())
+
+	_, err = fsc.NewCursor("not a token")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,55 @@
+package fsc
+
+import (
+	"cloud.google.com/go/firestore"
+	"golang.org/x/net/context"
+	"google.golang.org/api/iterator"
+)
+
+//isRecursiveDelete returns true if DELETE on supplied table deletes subcollections documents
+func (m *manager) isRecursiveDelete(table string) bool {
+	return m.tableFlag(table, recursiveDeleteKey)
+}
+
+//deleteWriter returns writer deleting matched document, and its subcollections documents in recursive delete mode
+func (m *manager) deleteWriter(table string) documentWriter {
+	var recursive = m.isRecursiveDelete(table)
+	return func(ctx context.Context, document *firestore.DocumentSnapshot) ([][]*write, error) {
+		var result = [][]*write{{{ref: document.Ref, delete: true}}}
+		if !recursive {
+			return result, nil
+		}
+		err := descendants(ctx, document.Ref, func(ref *firestore.DocumentRef) {
+			result = append(result, []*write{{ref: ref, delete: true}})
+		})
+		return result, err
+	}
+}
+
+//descendants passes references of all documents in subcollections of supplied document to handler, nested documents are passed first
+func descendants(ctx context.Context, ref *firestore.DocumentRef, handler func(ref *firestore.DocumentRef)) error {
+	collections := ref.Collections(ctx)
+	for {
+		collection, err := collections.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		documents := collection.DocumentRefs(ctx)
+		for {
+			document, err := documents.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if err = descendants(ctx, document, handler); err != nil {
+				return err
+			}
+			handler(document)
+		}
+	}
+}
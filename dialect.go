@@ -1,9 +1,11 @@
 package fsc
 
 import (
+	"cloud.google.com/go/firestore"
+	"fmt"
 	"github.com/viant/dsc"
+	"golang.org/x/net/context"
 	"google.golang.org/api/iterator"
-	"reflect"
 )
 
 var maxRecordColumnScan = 20
@@ -11,6 +13,9 @@ var maxRecordColumnScan = 20
 type dialect struct{ dsc.DatastoreDialect }
 
 func (d *dialect) GetKeyName(manager dsc.Manager, datastore, table string) string {
+	if descriptor, _ := d.descriptor(manager, table); descriptor != nil && descriptor.KeyColumn != "" {
+		return descriptor.KeyColumn
+	}
 	config := manager.Config()
 	if keyColumn := manager.Config().GetString(table+"."+pkColumnKey, ""); keyColumn != "" {
 		return keyColumn
@@ -18,9 +23,66 @@ func (d *dialect) GetKeyName(manager dsc.Manager, datastore, table string) strin
 	return config.GetString(pkColumnKey, "id")
 }
 
-//GetColumns returns estimated column for supplied table
+//descriptor returns table descriptor registered with CreateTable or nil, a connection is taken only when the descriptor is not cached
+func (d *dialect) descriptor(manager dsc.Manager, table string) (*TableDescriptor, error) {
+	fscManager, err := asManager(manager)
+	if err != nil {
+		return nil, err
+	}
+	if result, ok := fscManager.tables.get(table); ok {
+		return result, nil
+	}
+	connection, err := manager.ConnectionProvider().Get()
+	if err != nil {
+		return nil, err
+	}
+	defer connection.Close()
+	client, ctx, err := asClient(connection)
+	if err != nil {
+		return nil, err
+	}
+	return fscManager.tableDescriptor(client, ctx, table)
+}
+
+//CreateTable persists table descriptor in the metadata collection, specification is either *TableDescriptor or column definitions, i.e. "id string PRIMARY KEY, name string NOT NULL"
+func (d *dialect) CreateTable(manager dsc.Manager, datastore string, table string, specification interface{}) error {
+	fscManager, err := asManager(manager)
+	if err != nil {
+		return err
+	}
+	descriptor, err := asTableDescriptor(table, specification)
+	if err != nil {
+		return err
+	}
+	connection, err := manager.ConnectionProvider().Get()
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+	client, ctx, err := asClient(connection)
+	if err != nil {
+		return err
+	}
+	return fscManager.createTable(client, ctx, descriptor)
+}
+
+//GetColumns returns columns of the table descriptor, or columns inferred from sampled documents for supplied table without descriptor, nested map fields are returned as dotted columns
 func (d *dialect) GetColumns(manager dsc.Manager, datastore, table string) ([]dsc.Column, error) {
 	var result = make([]dsc.Column, 0)
+	descriptor, err := d.descriptor(manager, table)
+	if err != nil {
+		return result, err
+	}
+	if descriptor != nil {
+		for _, column := range descriptor.Columns {
+			result = append(result, dsc.NewSimpleColumn(column.Name, column.Type))
+		}
+		return result, nil
+	}
+	fscManager, err := asManager(manager)
+	if err != nil {
+		return result, err
+	}
 	connection, err := manager.ConnectionProvider().Get()
 	if err != nil {
 		return result, err
@@ -30,34 +92,29 @@ func (d *dialect) GetColumns(manager dsc.Manager, datastore, table string) ([]ds
 	if err != nil {
 		return result, err
 	}
-	collection := client.Collection(table).Limit(maxRecordColumnScan)
-	var columns = map[string]bool{}
-	iter := collection.Documents(ctx)
-	for i := 0; i < maxRecordColumnScan; i++ {
-		doc, err := iter.Next()
-
-		if err != nil {
-			if err == iterator.Done {
-				break
-			}
-			return nil, err
-		}
-		data := doc.Data()
-		if len(data) == 0 {
-			break
-		}
-		for k := range data {
-			if val, has := columns[k]; !has {
-				columns[k] = true
-				typeName := reflect.TypeOf(val).Name()
-				result = append(result, dsc.NewSimpleColumn(k, typeName))
-			}
-		}
+	sampleSize := fscManager.tableInt(table, sampleSizeKey, maxRecordColumnScan)
+	query, _ := fscManager.query(client, table, nil)
+	matcher := newPathMatcher(table, nil)
+	if matcher == nil {
+		query = query.Limit(sampleSize)
+	}
+	var inference = newColumnInference()
+	var sampled = 0
+	err = fscManager.readDocuments(ctx, query, matcher.filter(func(document *firestore.DocumentSnapshot) (bool, error) {
+		inference.add("", document.Data())
+		sampled++
+		return sampled < sampleSize, nil
+	}))
+	if err != nil {
+		return nil, err
+	}
+	for _, column := range inference.columns {
+		result = append(result, dsc.NewSimpleColumn(column, inference.typeName(column)))
 	}
 	return result, nil
 }
 
-//DropTable deletes table
+//DropTable deletes all table documents including subcollections documents with write batches, progress is logged after each committed batch, templated table drops documents of all parents matching the template
 func (d *dialect) DropTable(manager dsc.Manager, datastore string, table string) error {
 	connection, err := manager.ConnectionProvider().Get()
 	if err != nil {
@@ -68,8 +125,55 @@ func (d *dialect) DropTable(manager dsc.Manager, datastore string, table string)
 	if err != nil {
 		return err
 	}
-	_, err = client.Doc(table).Delete(ctx)
-	return err
+	batch := newBatch(client, ctx, manager.Config().GetInt(batchSizeKey, maxBatchSize))
+	var deleted = 0
+	var drop = func(ref *firestore.DocumentRef) {
+		batch.add([]*write{{ref: ref, delete: true}})
+		if batch.written > deleted {
+			deleted = batch.written
+			dsc.Logf("[%v]: deleted %v documents\n", table, deleted)
+		}
+	}
+	var documents func() (*firestore.DocumentRef, error)
+	if path, err := collectionPath(table, nil); err == nil {
+		documents = client.Collection(path).DocumentRefs(ctx).Next
+	} else {
+		matcher := newPathMatcher(table, nil)
+		iter := client.CollectionGroup(collectionID(table)).Select().Documents(ctx)
+		defer iter.Stop()
+		documents = func() (*firestore.DocumentRef, error) {
+			for {
+				snapshot, err := iter.Next()
+				if err != nil {
+					return nil, err
+				}
+				if matcher.matches(snapshot) {
+					return snapshot.Ref, nil
+				}
+			}
+		}
+	}
+	for {
+		document, err := documents()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err = descendants(ctx, document, drop); err != nil {
+			return err
+		}
+		drop(document)
+	}
+	if deleted, err = batch.close(); err != nil {
+		return err
+	}
+	dsc.Logf("[%v]: dropped, deleted %v documents\n", table, deleted)
+	if fscManager, err := asManager(manager); err == nil {
+		return fscManager.dropTableDescriptor(client, ctx, table)
+	}
+	return nil
 }
 
 //GetDatastores returns data stores
@@ -84,7 +188,7 @@ func (d *dialect) GetCurrentDatastore(manager dsc.Manager) (string, error) {
 	return config.Get(databaseURLKey), nil
 }
 
-//GetTables returns tables
+//GetTables returns root collections, with tableDepth subcollections up to the depth are returned as templated paths, i.e. users/{id}/orders, once per distinct path
 func (d *dialect) GetTables(manager dsc.Manager, datastore string) ([]string, error) {
 	var result = []string{}
 	connection, err := manager.ConnectionProvider().Get()
@@ -104,15 +208,75 @@ func (d *dialect) GetTables(manager dsc.Manager, datastore string) ([]string, er
 	if err != nil {
 		return result, err
 	}
+	fscManager, err := asManager(manager)
+	if err != nil {
+		return result, err
+	}
+	metadataCollection := manager.Config().GetString(metadataCollectionKey, defaultMetadataCollection)
+	depth := manager.Config().GetInt(tableDepthKey, 0)
+	var tables = make(map[string]bool)
+	var add = func(table string) {
+		if !tables[table] {
+			tables[table] = true
+			result = append(result, table)
+		}
+	}
 	for _, item := range references {
-		result = append(result, item.Path)
+		if item.ID == metadataCollection {
+			continue
+		}
+		add(relativePath(item.Path))
+		if depth > 0 {
+			if err = fscManager.walkSubcollections(ctx, item, relativePath(item.Path), depth, add); err != nil {
+				return result, err
+			}
+		}
 	}
 	return result, nil
 }
 
-//CanPersistBatch returns false
+//walkSubcollections passes templated paths of subcollections of sampled collection documents to handler, down to supplied depth, a placeholder is named after the singular collection ID and its key column, i.e. users/{userId}/orders
+func (m *manager) walkSubcollections(ctx context.Context, collection *firestore.CollectionRef, table string, depth int, handler func(table string)) error {
+	placeholder := placeholderName(collection.ID, m.getKeyColumn(table))
+	sampleSize := m.tableInt(table, sampleSizeKey, maxRecordColumnScan)
+	documents := collection.DocumentRefs(ctx)
+	for sampled := 0; sampled < sampleSize; sampled++ {
+		document, err := documents.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		subcollections, err := document.Collections(ctx).GetAll()
+		if err != nil {
+			return err
+		}
+		for _, subcollection := range subcollections {
+			subcollectionTable := table + "/{" + placeholder + "}/" + subcollection.ID
+			handler(subcollectionTable)
+			if depth > 1 {
+				if err = m.walkSubcollections(ctx, subcollection, subcollectionTable, depth-1, handler); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+//CanPersistBatch returns true, records are persisted with firestore write batches
 func (d *dialect) CanPersistBatch() bool {
-	return false
+	return true
+}
+
+//asManager returns firestore manager
+func asManager(candidate dsc.Manager) (*manager, error) {
+	result, ok := candidate.(*manager)
+	if !ok {
+		return nil, fmt.Errorf("unsupported manager: %T", candidate)
+	}
+	return result, nil
 }
 
 func newDialect() dsc.DatastoreDialect {
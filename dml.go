@@ -0,0 +1,231 @@
+package fsc
+
+import (
+	"cloud.google.com/go/firestore"
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"regexp"
+	"strings"
+)
+
+const (
+	incrementFunction        = "INCREMENT"
+	decrementFunction        = "DECREMENT"
+	arrayUnionFunction       = "ARRAY_UNION"
+	arrayRemoveFunction      = "ARRAY_REMOVE"
+	currentTimestampFunction = "CURRENT_TIMESTAMP"
+	nullFunction             = "NULL"
+)
+
+//upsertExpression matches MySQL style upsert suffix
+var upsertExpression = regexp.MustCompile(`(?is)\s+ON\s+DUPLICATE\s+KEY\s+UPDATE\b.*$`)
+
+//updateExpression matches UPDATE statement with its SET and WHERE clauses
+var updateExpression = regexp.MustCompile(`(?is)^(\s*UPDATE\s+\S+\s+SET\s+)(.+)$`)
+
+//whereExpression matches WHERE keyword
+var whereExpression = regexp.MustCompile(`(?i)\s+WHERE\s+`)
+
+//arithmeticExpression matches column + value or column - value expression
+var arithmeticExpression = regexp.MustCompile(`(?s)^([\w.]+)\s*([+-])\s*(.+)$`)
+
+//arrayExpression matches ARRAY_UNION(column, value) or ARRAY_REMOVE(column, value) expression
+var arrayExpression = regexp.MustCompile(`(?is)^(ARRAY_UNION|ARRAY_REMOVE)\s*\(\s*([\w.]+)\s*,\s*(.+)\)$`)
+
+//timestampExpression matches CURRENT_TIMESTAMP or NOW() expression
+var timestampExpression = regexp.MustCompile(`(?i)^(CURRENT_TIMESTAMP(\s*\(\s*\))?|NOW\s*\(\s*\))$`)
+
+//dmlStatement represents DML statement with firestore specific extensions
+type dmlStatement struct {
+	*dsc.DmlStatement
+	upsert    bool
+	functions map[string]string
+	literals  map[string]interface{}
+}
+
+//isQuoted returns true if text ends inside quoted literal
+func isQuoted(text string) bool {
+	var quote rune
+	for _, char := range text {
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '\'' || char == '"':
+			quote = char
+		}
+	}
+	return quote != 0
+}
+
+//splitWhere splits SET clause from WHERE clause, WHERE keyword inside quoted literal is ignored
+func splitWhere(clause string) (string, string) {
+	for _, index := range whereExpression.FindAllStringIndex(clause, -1) {
+		if !isQuoted(clause[:index[0]]) {
+			return clause[:index[0]], clause[index[0]:]
+		}
+	}
+	return clause, ""
+}
+
+//splitAssignments splits SET clause by top level commas
+func splitAssignments(clause string) []string {
+	var result = make([]string, 0)
+	var depth = 0
+	var quote rune
+	var start = 0
+	for i, char := range clause {
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '\'' || char == '"':
+			quote = char
+		case char == '(':
+			depth++
+		case char == ')':
+			depth--
+		case char == ',' && depth == 0:
+			result = append(result, clause[start:i])
+			start = i + 1
+		}
+	}
+	return append(result, clause[start:])
+}
+
+//asOperand returns transform operand literal value, multiple comma separated literals are returned as slice, ok is false if operand uses bind parameter
+func asOperand(operand string) (interface{}, bool) {
+	var values = make([]interface{}, 0)
+	for _, item := range splitAssignments(operand) {
+		if strings.TrimSpace(item) == "?" {
+			return nil, false
+		}
+		values = append(values, asLiteral(item))
+	}
+	if len(values) == 1 {
+		return values[0], true
+	}
+	return values, true
+}
+
+//asAssignment returns plain assignment, column, function and literal operand for supplied SET assignment with firestore transform expression, literal operand is nil if operand uses bind parameter
+func asAssignment(assignment string) (string, string, string, interface{}) {
+	index := strings.Index(assignment, "=")
+	if index == -1 {
+		return assignment, "", "", nil
+	}
+	column := strings.TrimSpace(assignment[:index])
+	expression := strings.TrimSpace(assignment[index+1:])
+	var function, operand string
+	if matched := arithmeticExpression.FindStringSubmatch(expression); len(matched) > 0 && matched[1] == column {
+		function, operand = incrementFunction, matched[3]
+		if matched[2] == "-" {
+			function = decrementFunction
+		}
+	} else if matched := arrayExpression.FindStringSubmatch(expression); len(matched) > 0 && matched[2] == column {
+		function, operand = strings.ToUpper(matched[1]), matched[3]
+	} else if timestampExpression.MatchString(expression) {
+		return column + " = ''", column, currentTimestampFunction, nil
+	} else if strings.ToUpper(expression) == nullFunction {
+		return column + " = ''", column, nullFunction, nil
+	} else {
+		return assignment, "", "", nil
+	}
+	if literal, ok := asOperand(operand); ok {
+		return column + " = ''", column, function, literal
+	}
+	return column + " = " + strings.TrimSpace(operand), column, function, nil
+}
+
+//rewriteUpdate rewrites UPDATE SET transform expressions into plain assignments, bind parameters order is preserved, literal transform operands are returned by column
+func rewriteUpdate(dml string) (string, map[string]string, map[string]interface{}) {
+	var functions = make(map[string]string)
+	var literals = make(map[string]interface{})
+	matched := updateExpression.FindStringSubmatch(dml)
+	if len(matched) == 0 {
+		return dml, functions, literals
+	}
+	setClause, whereClause := splitWhere(matched[2])
+	var assignments = make([]string, 0)
+	for _, item := range splitAssignments(setClause) {
+		assignment, column, function, literal := asAssignment(strings.TrimSpace(item))
+		if function != "" {
+			functions[column] = function
+		}
+		if literal != nil {
+			literals[column] = literal
+		}
+		assignments = append(assignments, assignment)
+	}
+	return matched[1] + strings.Join(assignments, ", ") + whereClause, functions, literals
+}
+
+//parseDML parses DML, INSERT ... ON DUPLICATE KEY UPDATE suffix is stripped and reported as upsert, UPDATE transform expressions are reported as column functions
+func parseDML(dml string) (*dmlStatement, error) {
+	var upsert = upsertExpression.MatchString(dml)
+	if upsert {
+		dml = upsertExpression.ReplaceAllString(dml, "")
+	}
+	dml, functions, literals := rewriteUpdate(dml)
+	parser := dsc.NewDmlParser()
+	statement, err := parser.Parse(dml)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %v due to %v", dml, err)
+	}
+	return &dmlStatement{DmlStatement: statement, upsert: upsert, functions: functions, literals: literals}, nil
+}
+
+//negate returns negated numeric value
+func negate(value interface{}) interface{} {
+	switch actual := value.(type) {
+	case int:
+		return -actual
+	case int64:
+		return -actual
+	case float64:
+		return -actual
+	}
+	return -toolbox.AsFloat(value)
+}
+
+//asTransformValue returns firestore transform sentinel for supplied column function
+func asTransformValue(function string, value interface{}, nullAsDelete bool) interface{} {
+	var values = []interface{}{value}
+	if toolbox.IsSlice(value) {
+		values = toolbox.AsSlice(value)
+	}
+	switch function {
+	case incrementFunction:
+		return firestore.Increment(value)
+	case decrementFunction:
+		return firestore.Increment(negate(value))
+	case arrayUnionFunction:
+		return firestore.ArrayUnion(values...)
+	case arrayRemoveFunction:
+		return firestore.ArrayRemove(values...)
+	case currentTimestampFunction:
+		return firestore.ServerTimestamp
+	case nullFunction:
+		if nullAsDelete {
+			return firestore.Delete
+		}
+		return nil
+	}
+	return value
+}
+
+//applyFunctions replaces record values of transformed columns with firestore transform sentinels, literal operands take precedence over parsed record values
+func (m *manager) applyFunctions(statement *dmlStatement, record map[string]interface{}) {
+	var nullAsDelete = m.tableFlag(statement.Table, nullAsDeleteKey)
+	for column, function := range statement.functions {
+		if literal, ok := statement.literals[column]; ok {
+			record[column] = literal
+		}
+		if value, ok := record[column]; ok {
+			record[column] = asTransformValue(function, value, nullAsDelete)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package fsc
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSplitAssignments(t *testing.T) {
+	var useCases = []struct {
+		description string
+		clause      string
+		expected    []string
+	}{
+		{
+			description: "plain assignments",
+			clause:      "name = ?, age = ?",
+			expected:    []string{"name = ?", " age = ?"},
+		},
+		{
+			description: "quoted comma",
+			clause:      "name = 'a, b', note = \"c, d\"",
+			expected:    []string{"name = 'a, b'", " note = \"c, d\""},
+		},
+		{
+			description: "nested parentheses",
+			clause:      "tags = ARRAY_UNION(tags, (1), 2), name = ?",
+			expected:    []string{"tags = ARRAY_UNION(tags, (1), 2)", " name = ?"},
+		},
+		{
+			description: "parenthesis inside literal",
+			clause:      "name = 'a)', age = ?",
+			expected:    []string{"name = 'a)'", " age = ?"},
+		},
+	}
+	for _, useCase := range useCases {
+		assert.EqualValues(t, useCase.expected, splitAssignments(useCase.clause), useCase.description)
+	}
+}
+
+func TestAsAssignment(t *testing.T) {
+	var useCases = []struct {
+		description string
+		assignment  string
+		expected    string
+		column      string
+		function    string
+		literal     interface{}
+	}{
+		{
+			description: "plain assignment",
+			assignment:  "name = ?",
+			expected:    "name = ?",
+		},
+		{
+			description: "increment parameter",
+			assignment:  "count = count + ?",
+			expected:    "count = ?",
+			column:      "count",
+			function:    incrementFunction,
+		},
+		{
+			description: "increment literal",
+			assignment:  "count = count + 1",
+			expected:    "count = ''",
+			column:      "count",
+			function:    incrementFunction,
+			literal:     int64(1),
+		},
+		{
+			description: "decrement float literal",
+			assignment:  "stats.score = stats.score - 1.5",
+			expected:    "stats.score = ''",
+			column:      "stats.score",
+			function:    decrementFunction,
+			literal:     1.5,
+		},
+		{
+			description: "other column arithmetic",
+			assignment:  "count = total + 1",
+			expected:    "count = total + 1",
+		},
+		{
+			description: "array union parameter",
+			assignment:  "tags = ARRAY_UNION(tags, ?)",
+			expected:    "tags = ?",
+			column:      "tags",
+			function:    arrayUnionFunction,
+		},
+		{
+			description: "array remove literals",
+			assignment:  "tags = array_remove(tags, 'a, b', 'c')",
+			expected:    "tags = ''",
+			column:      "tags",
+			function:    arrayRemoveFunction,
+			literal:     []interface{}{"a, b", "c"},
+		},
+		{
+			description: "current timestamp",
+			assignment:  "modified = NOW()",
+			expected:    "modified = ''",
+			column:      "modified",
+			function:    currentTimestampFunction,
+		},
+		{
+			description: "null",
+			assignment:  "note = null",
+			expected:    "note = ''",
+			column:      "note",
+			function:    nullFunction,
+		},
+	}
+	for _, useCase := range useCases {
+		assignment, column, function, literal := asAssignment(useCase.assignment)
+		assert.Equal(t, useCase.expected, assignment, useCase.description)
+		assert.Equal(t, useCase.column, column, useCase.description)
+		assert.Equal(t, useCase.function, function, useCase.description)
+		assert.EqualValues(t, useCase.literal, literal, useCase.description)
+	}
+}
+
+func TestRewriteUpdate(t *testing.T) {
+	var useCases = []struct {
+		description string
+		dml         string
+		expected    string
+		functions   map[string]string
+		literals    map[string]interface{}
+	}{
+		{
+			description: "not update",
+			dml:         "INSERT INTO users(id, name) VALUES(?, ?)",
+			expected:    "INSERT INTO users(id, name) VALUES(?, ?)",
+			functions:   map[string]string{},
+			literals:    map[string]interface{}{},
+		},
+		{
+			description: "transforms",
+			dml:         "UPDATE users SET count = count + ?, tags = ARRAY_UNION(tags, 'x', 'y'), modified = CURRENT_TIMESTAMP WHERE id = ?",
+			expected:    "UPDATE users SET count = ?, tags = '', modified = '' WHERE id = ?",
+			functions:   map[string]string{"count": incrementFunction, "tags": arrayUnionFunction, "modified": currentTimestampFunction},
+			literals:    map[string]interface{}{"tags": []interface{}{"x", "y"}},
+		},
+		{
+			description: "where inside literal",
+			dml:         "UPDATE users SET note = 'stop where you are', count = count - 2 WHERE id = ?",
+			expected:    "UPDATE users SET note = 'stop where you are', count = '' WHERE id = ?",
+			functions:   map[string]string{"count": decrementFunction},
+			literals:    map[string]interface{}{"count": int64(2)},
+		},
+		{
+			description: "without where",
+			dml:         "UPDATE users SET note = 'a, b', visits = visits + 1",
+			expected:    "UPDATE users SET note = 'a, b', visits = ''",
+			functions:   map[string]string{"visits": incrementFunction},
+			literals:    map[string]interface{}{"visits": int64(1)},
+		},
+	}
+	for _, useCase := range useCases {
+		dml, functions, literals := rewriteUpdate(useCase.dml)
+		assert.Equal(t, useCase.expected, dml, useCase.description)
+		assert.EqualValues(t, useCase.functions, functions, useCase.description)
+		assert.EqualValues(t, useCase.literals, literals, useCase.description)
+	}
+}
@@ -0,0 +1,292 @@
+package fsc
+
+import (
+	"cloud.google.com/go/firestore"
+	"encoding/json"
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"golang.org/x/net/context"
+	"sort"
+	"time"
+)
+
+//accumulator represents aggregation function state
+type accumulator struct {
+	*aggregate
+	count   int64
+	sum     float64
+	isFloat bool
+	value   interface{}
+}
+
+//add adds document value to the accumulator
+func (a *accumulator) add(document *firestore.DocumentSnapshot) {
+	if a.argument == "*" || a.argument == "" {
+		a.count++
+		return
+	}
+	value, err := document.DataAt(a.argument)
+	if err != nil || value == nil {
+		return
+	}
+	a.count++
+	switch a.function {
+	case "SUM", "AVG":
+		switch actual := value.(type) {
+		case int64:
+			a.sum += float64(actual)
+		case float64:
+			a.isFloat = true
+			a.sum += actual
+		default:
+			a.isFloat = true
+			a.sum += toolbox.AsFloat(value)
+		}
+	case "MIN":
+		if a.value == nil || compareValues(value, a.value) < 0 {
+			a.value = value
+		}
+	case "MAX":
+		if a.value == nil || compareValues(value, a.value) > 0 {
+			a.value = value
+		}
+	}
+}
+
+//result returns aggregated value
+func (a *accumulator) result() interface{} {
+	switch a.function {
+	case "COUNT":
+		return a.count
+	case "SUM":
+		if a.isFloat {
+			return a.sum
+		}
+		return int64(a.sum)
+	case "AVG":
+		if a.count == 0 {
+			return nil
+		}
+		return a.sum / float64(a.count)
+	}
+	return a.value
+}
+
+//group represents a single GROUP BY row
+type group struct {
+	values       map[string]interface{}
+	accumulators []*accumulator
+}
+
+//record returns group row
+func (g *group) record() map[string]interface{} {
+	var result = make(map[string]interface{})
+	for k, v := range g.values {
+		result[k] = v
+	}
+	for _, accumulator := range g.accumulators {
+		result[accumulator.alias] = accumulator.result()
+	}
+	return result
+}
+
+//grouping represents client side streaming aggregation
+type grouping struct {
+	columns    []*dsc.SQLColumn
+	aggregates []*aggregate
+	maxGroups  int
+	groups     map[string]*group
+	keys       []string
+}
+
+//add adds document to its group
+func (g *grouping) add(document *firestore.DocumentSnapshot) (bool, error) {
+	var values = make(map[string]interface{})
+	var keyValues = make([]interface{}, 0)
+	for _, column := range g.columns {
+		value, _ := document.DataAt(column.Name)
+		name := column.Name
+		if column.Alias != "" {
+			name = column.Alias
+		}
+		values[name] = value
+		keyValues = append(keyValues, value)
+	}
+	encoded, err := json.Marshal(keyValues)
+	if err != nil {
+		return false, err
+	}
+	key := string(encoded)
+	aGroup, ok := g.groups[key]
+	if !ok {
+		if len(g.groups) >= g.maxGroups {
+			return false, fmt.Errorf("too many groups: exceeded %v limit, increase %v or narrow criteria", g.maxGroups, maxGroupsKey)
+		}
+		aGroup = g.newGroup(values)
+		g.groups[key] = aGroup
+		g.keys = append(g.keys, key)
+	}
+	for _, accumulator := range aGroup.accumulators {
+		accumulator.add(document)
+	}
+	return true, nil
+}
+
+func (g *grouping) newGroup(values map[string]interface{}) *group {
+	var result = &group{values: values, accumulators: make([]*accumulator, 0)}
+	for _, aggregate := range g.aggregates {
+		result.accumulators = append(result.accumulators, &accumulator{aggregate: aggregate})
+	}
+	return result
+}
+
+//records returns group rows ordered and paged with supplied paging
+func (g *grouping) records(paging *paging) []map[string]interface{} {
+	var result = make([]map[string]interface{}, 0)
+	if len(g.keys) == 0 && len(g.columns) == 0 {
+		result = append(result, g.newGroup(map[string]interface{}{}).record())
+	}
+	for _, key := range g.keys {
+		result = append(result, g.groups[key].record())
+	}
+	if len(paging.orderBy) > 0 {
+		sort.SliceStable(result, func(i, j int) bool {
+			for _, column := range paging.orderBy {
+				name := column.Name
+				if column.Alias != "" {
+					name = column.Alias
+				}
+				comparison := compareValues(result[i][name], result[j][name])
+				if comparison == 0 {
+					continue
+				}
+				if column.IsDesc {
+					return comparison > 0
+				}
+				return comparison < 0
+			}
+			return false
+		})
+	}
+	if paging.offset > 0 {
+		if paging.offset >= len(result) {
+			return result[:0]
+		}
+		result = result[paging.offset:]
+	}
+	if paging.limit > 0 && paging.limit < len(result) {
+		result = result[:paging.limit]
+	}
+	return result
+}
+
+//fields returns document fields needed for grouping
+func (g *grouping) fields() []string {
+	var result = make([]string, 0)
+	for _, column := range g.columns {
+		result = append(result, column.Name)
+	}
+	for _, aggregate := range g.aggregates {
+		if aggregate.argument != "*" && aggregate.argument != "" {
+			result = append(result, aggregate.argument)
+		}
+	}
+	return result
+}
+
+func newGrouping(statement *dsc.QueryStatement, aggregates []*aggregate, maxGroups int) (*grouping, error) {
+	var grouped = make(map[string]bool)
+	for _, column := range statement.GroupBy {
+		grouped[column.Name] = true
+	}
+	var result = &grouping{
+		columns:    make([]*dsc.SQLColumn, 0),
+		aggregates: aggregates,
+		maxGroups:  maxGroups,
+		groups:     make(map[string]*group),
+		keys:       make([]string, 0),
+	}
+	for _, column := range statement.Columns {
+		if aggregate, _ := asAggregate(column); aggregate != nil {
+			continue
+		}
+		if !grouped[column.Name] {
+			return nil, fmt.Errorf("column %v must be used in GROUP BY or aggregation function", column.Name)
+		}
+		result.columns = append(result.columns, column)
+	}
+	return result, nil
+}
+
+func asNumber(value interface{}) (float64, bool) {
+	switch actual := value.(type) {
+	case int64:
+		return float64(actual), true
+	case float64:
+		return actual, true
+	case int:
+		return float64(actual), true
+	}
+	return 0, false
+}
+
+//compareValues compares two document values, nil is the smallest value
+func compareValues(left, right interface{}) int {
+	if left == nil || right == nil {
+		switch {
+		case left == nil && right == nil:
+			return 0
+		case left == nil:
+			return -1
+		}
+		return 1
+	}
+	if leftTime, ok := left.(time.Time); ok {
+		if rightTime, ok := right.(time.Time); ok {
+			switch {
+			case leftTime.Before(rightTime):
+				return -1
+			case leftTime.After(rightTime):
+				return 1
+			}
+			return 0
+		}
+	}
+	leftNumber, isLeftNumber := asNumber(left)
+	rightNumber, isRightNumber := asNumber(right)
+	if isLeftNumber && isRightNumber {
+		switch {
+		case leftNumber < rightNumber:
+			return -1
+		case leftNumber > rightNumber:
+			return 1
+		}
+		return 0
+	}
+	leftText, rightText := toolbox.AsString(left), toolbox.AsString(right)
+	switch {
+	case leftText < rightText:
+		return -1
+	case leftText > rightText:
+		return 1
+	}
+	return 0
+}
+
+//readGroups streams documents matched by query into groups and emits one row per group, ORDER BY, OFFSET and LIMIT apply to group rows
+func (m *manager) readGroups(ctx context.Context, query firestore.Query, matcher *pathMatcher, grouping *grouping, paging *paging, scanner *dsc.SQLScanner, readingHandler func(scanner dsc.Scanner) (toContinue bool, err error)) error {
+	if fields := grouping.fields(); len(fields) > 0 {
+		query = query.Select(fields...)
+	}
+	if err := m.readDocuments(ctx, query, matcher.filter(grouping.add)); err != nil {
+		return err
+	}
+	for _, record := range grouping.records(paging) {
+		scanner.Values = record
+		if cont, err := readingHandler(scanner); err != nil || !cont {
+			return err
+		}
+	}
+	return nil
+}
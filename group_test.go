@@ -0,0 +1,29 @@
+package fsc
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCompareValues(t *testing.T) {
+	now := time.Now()
+	var useCases = []struct {
+		description string
+		left        interface{}
+		right       interface{}
+		expected    int
+	}{
+		{description: "nil values", expected: 0},
+		{description: "nil is smallest", right: int64(1), expected: -1},
+		{description: "nil is smallest reversed", left: "a", expected: 1},
+		{description: "int and float", left: int64(2), right: 2.5, expected: -1},
+		{description: "equal numbers", left: 3, right: 3.0, expected: 0},
+		{description: "times", left: now.Add(time.Second), right: now, expected: 1},
+		{description: "texts", left: "a", right: "b", expected: -1},
+		{description: "number and text", left: int64(10), right: "9", expected: -1},
+	}
+	for _, useCase := range useCases {
+		assert.Equal(t, useCase.expected, compareValues(useCase.left, useCase.right), useCase.description)
+	}
+}
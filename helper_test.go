@@ -21,6 +21,18 @@ func getTestConfig(t *testing.T) (*dsc.Config, error) {
 	})
 }
 
+//newTestManager returns manager created with test config extended with supplied parameters
+func newTestManager(t *testing.T, parameters map[string]interface{}) (dsc.Manager, error) {
+	config, err := getTestConfig(t)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range parameters {
+		config.Parameters[key] = value
+	}
+	return dsc.NewManagerFactory().Create(config)
+}
+
 func getEnvValue(key, defaultValue string) string {
 	value := os.Getenv(key)
 	if value == "" {
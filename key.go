@@ -0,0 +1,74 @@
+package fsc
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"reflect"
+	"strings"
+)
+
+//InsertResult represents INSERT result with generated document ID
+type InsertResult struct {
+	sql.Result
+	ID string
+}
+
+//LastInsertId returns an error since generated firestore document IDs are not numeric, use ID or GeneratedID instead
+func (r *InsertResult) LastInsertId() (int64, error) {
+	return 0, fmt.Errorf("generated document ID %v is not numeric", r.ID)
+}
+
+//GeneratedID returns document ID generated by INSERT or empty string if the ID was supplied
+func GeneratedID(result sql.Result) string {
+	if insertResult, ok := result.(*InsertResult); ok {
+		return insertResult.ID
+	}
+	return ""
+}
+
+//setGeneratedKey sets generated document ID on the source struct string field matched by column tag or field name
+func setGeneratedKey(source interface{}, writes []*write) {
+	for _, write := range writes {
+		if write.generatedKey == "" {
+			continue
+		}
+		value := reflect.ValueOf(source)
+		if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+			return
+		}
+		value = value.Elem()
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			column := field.Tag.Get("column")
+			if column == "" {
+				column = field.Name
+			}
+			if !strings.EqualFold(column, write.generatedKey) {
+				continue
+			}
+			if field.PkgPath == "" && value.Field(i).Kind() == reflect.String {
+				value.Field(i).SetString(write.ref.ID)
+			}
+			break
+		}
+	}
+}
+
+//withoutKeys returns records with key values and records without key values for ID generation
+func withoutKeys(dataPointer interface{}, provider dsc.KeyGetter) (interface{}, []interface{}) {
+	var keyed = make([]interface{}, 0)
+	var generated = make([]interface{}, 0)
+	toolbox.ProcessSlice(dataPointer, func(item interface{}) bool {
+		for _, key := range provider.Key(item) {
+			if key != nil && toolbox.AsString(key) != "" {
+				keyed = append(keyed, item)
+				return true
+			}
+		}
+		generated = append(generated, item)
+		return true
+	})
+	return &keyed, generated
+}
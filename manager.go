@@ -8,134 +8,304 @@ import (
 	"github.com/viant/toolbox"
 	"github.com/viant/toolbox/data"
 	"golang.org/x/net/context"
-	"google.golang.org/api/iterator"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
 	"strings"
 )
 
 const (
-	pkColumnKey = "keyColumn"
+	pkColumnKey               = "keyColumn"
+	maxGroupsKey              = "maxGroups"
+	collectionGroupKey        = "collectionGroup"
+	batchSizeKey              = "batchSize"
+	transactionLimitKey       = "transactionLimit"
+	recursiveDeleteKey        = "recursiveDelete"
+	metadataCollectionKey     = "metadataCollection"
+	descriptorTTLKey          = "descriptorTTL"
+	sampleSizeKey             = "sampleSize"
+	schemaKey                 = "schema"
+	validationKey             = "validation"
+	tableDepthKey             = "tableDepth"
+	bulkWriteKey              = "bulkWrite"
+	updateTimeColumnKey       = "updateTimeColumn"
+	versionColumnKey          = "versionColumn"
+	upsertKey                 = "upsert"
+	autoIDKey                 = "autoID"
+	subDocumentUpdateKey      = "subDocumentUpdate"
+	nullAsDeleteKey           = "nullAsDelete"
+	parentColumn              = "_parent"
+	defaultGroups             = 100000
+	defaultMetadataCollection = "_fsc_tables"
+	defaultDescriptorTTL      = 60
 )
 
 type config struct {
 	*dsc.Config
 	keyColumnName string
 	dbName        string
+	maxGroups     int
+	bulkWrite     bool
 }
 
 type manager struct {
 	*dsc.AbstractManager
-	config *config
+	config  *config
+	tables  *tableRegistry
+	schemas *tableRegistry
 }
 
 func (m *manager) getKeyColumn(table string) string {
+	if descriptor, _ := m.tables.get(table); descriptor != nil && descriptor.KeyColumn != "" {
+		return descriptor.KeyColumn
+	}
 	if keyColumn := m.config.GetString(table+"."+pkColumnKey, ""); keyColumn != "" {
 		return keyColumn
 	}
 	return m.config.keyColumnName
 }
 
-func (m *manager) insert(client *firestore.Client, ctx context.Context, statement *dsc.DmlStatement, sqlParameters []interface{}) (err error) {
+//tableFlag returns table boolean parameter, <table>.<key> parameter takes precedence over <key> parameter
+func (m *manager) tableFlag(table, key string) bool {
+	return m.config.GetBoolean(table+"."+key, m.config.GetBoolean(key, false))
+}
+
+//tableString returns table string parameter, <table>.<key> parameter takes precedence over <key> parameter
+func (m *manager) tableString(table, key string) string {
+	if value := m.config.GetString(table+"."+key, ""); value != "" {
+		return value
+	}
+	return m.config.GetString(key, "")
+}
+
+//tableInt returns table int parameter, <table>.<key> parameter takes precedence over <key> parameter
+func (m *manager) tableInt(table, key string, defaultValue int) int {
+	return m.config.GetInt(table+"."+key, m.config.GetInt(key, defaultValue))
+}
+
+//isCollectionGroup returns true if table is configured to query all collections with the table ID
+func (m *manager) isCollectionGroup(table string) bool {
+	return m.config.GetBoolean(table+"."+collectionGroupKey, false)
+}
+
+//query returns table query, collection group query is used for configured tables and templated tables with unbound placeholders
+func (m *manager) query(client *firestore.Client, table string, bindings map[string]interface{}) (query firestore.Query, isCollectionGroup bool) {
+	if m.isCollectionGroup(table) {
+		return client.CollectionGroup(table).Query, true
+	}
+	path, err := collectionPath(table, bindings)
+	if err != nil {
+		return client.CollectionGroup(collectionID(table)).Query, true
+	}
+	return client.Collection(path).Query, false
+}
+
+//isUpsert returns true if insert into supplied table merges existing document
+func (m *manager) isUpsert(table string) bool {
+	return m.tableFlag(table, upsertKey)
+}
+
+//isSubDocumentUpdate returns true if dotted columns of supplied table are updated in sub documents
+func (m *manager) isSubDocumentUpdate(table string) bool {
+	return m.tableFlag(table, subDocumentUpdateKey)
+}
+
+//isAutoID returns true if insert into supplied table generates missing document ID
+func (m *manager) isAutoID(table string) bool {
+	return m.tableFlag(table, autoIDKey)
+}
+
+func (m *manager) insertWrites(client *firestore.Client, statement *dmlStatement, sqlParameters []interface{}) (writes []*write, err error) {
 	parameters := toolbox.NewSliceIterator(sqlParameters)
 	var record map[string]interface{}
 
 	if record, err = statement.ColumnValueMap(parameters); err != nil {
-		return err
+		return nil, err
 	}
 	keyColumn := m.getKeyColumn(statement.Table)
 	id, ok := record[keyColumn]
-	if !ok {
-		return fmt.Errorf("missing value for %v", keyColumn)
+	var generated = !ok || id == nil || toolbox.AsString(id) == ""
+	if generated && !m.isAutoID(statement.Table) {
+		return nil, fmt.Errorf("missing value for %v", keyColumn)
 	}
-	pathRef := statement.Table
-	ref := client.Collection(pathRef).Doc(toolbox.AsString(id))
-	_, err = ref.Set(ctx, record)
-	return err
+	pathRef, err := collectionPath(statement.Table, record)
+	if err != nil {
+		return nil, err
+	}
+	upsert := statement.upsert || m.isUpsert(statement.Table)
+	if upsert && m.getVersionColumn(statement.Table) != "" {
+		return nil, fmt.Errorf("upsert is not supported with version column: %v", statement.Table)
+	}
+	m.initVersion(statement.Table, record)
+	var ref *firestore.DocumentRef
+	if generated {
+		ref = client.Collection(pathRef).NewDoc()
+		record[keyColumn] = ref.ID
+	} else {
+		ref = client.Collection(pathRef).Doc(toolbox.AsString(id))
+	}
+	if descriptor := m.schema(statement.Table); descriptor != nil {
+		if err = descriptor.checkRequired(record, false); err != nil {
+			return nil, err
+		}
+	}
+	if err = m.validate(statement.Table, record, nil); err != nil {
+		return nil, err
+	}
+	var result = &write{ref: ref, record: record, create: !upsert, merge: upsert}
+	if generated {
+		result.generatedKey = keyColumn
+	}
+	return []*write{result}, nil
+}
+
+func (m *manager) insert(client *firestore.Client, ctx context.Context, statement *dmlStatement, sqlParameters []interface{}) (id string, err error) {
+	writes, err := m.insertWrites(client, statement, sqlParameters)
+	if err != nil {
+		return "", err
+	}
+	if writes[0].generatedKey != "" {
+		id = writes[0].ref.ID
+	}
+	return id, m.applyWrites(client, ctx, writes)
 }
 
-func (m *manager) update(client *firestore.Client, ctx context.Context, statement *dsc.DmlStatement, sqlParameters []interface{}) (err error) {
+func (m *manager) updateWrites(client *firestore.Client, ctx context.Context, statement *dmlStatement, sqlParameters []interface{}) (writes []*write, err error) {
 	parameters := toolbox.NewSliceIterator(sqlParameters)
 	var record map[string]interface{}
 	if record, err = statement.ColumnValueMap(parameters); err != nil {
-		return err
+		return nil, err
 	}
 	criteriaMap, err := m.criteria(statement.BaseStatement, parameters)
+	if err != nil {
+		return nil, err
+	}
 	keyColumn := m.getKeyColumn(statement.Table)
 	id, ok := criteriaMap[keyColumn]
 	if !ok {
-		return fmt.Errorf("missing value for %v", keyColumn)
+		return nil, fmt.Errorf("missing value for %v", keyColumn)
 	}
 	for k, v := range criteriaMap {
 		record[k] = v
 	}
-	pathRef := statement.Table //+ "/" + toolbox.AsString(id)
+	pathRef, err := collectionPath(statement.Table, record)
+	if err != nil {
+		return nil, err
+	}
 	ref := client.Collection(pathRef).Doc(toolbox.AsString(id))
-	var nodeValues = data.NewMap()
-	var nodeKeys = make(map[string]bool)
+	return m.documentUpdateWrites(client, ctx, statement, ref, record)
+}
+
+//documentUpdateWrites returns writes updating supplied document with the record
+func (m *manager) documentUpdateWrites(client *firestore.Client, ctx context.Context, statement *dmlStatement, ref *firestore.DocumentRef, record map[string]interface{}) (writes []*write, err error) {
+	preconditions, err := m.preconditions(ctx, statement.Table, ref, record)
+	if err != nil {
+		return nil, err
+	}
+	if err = m.validate(statement.Table, record, statement.functions); err != nil {
+		return nil, err
+	}
+	m.applyFunctions(statement, record)
+	if descriptor := m.schema(statement.Table); descriptor != nil {
+		if err = descriptor.checkRequired(record, true); err != nil {
+			return nil, err
+		}
+	}
+	if m.isSubDocumentUpdate(statement.Table) {
+		return m.subDocumentWrites(client, ctx, ref, record, preconditions), nil
+	}
+	writes = make([]*write, 0)
 	if len(record) > 0 {
 		var updates = make([]firestore.Update, 0)
 		for k, v := range record {
-			if strings.Contains(k, ".") {
-				node := string(k[:strings.LastIndex(k, ".")])
-				nodeKeys[node] = true
-				fmt.Printf("setting %v %v\n", k, v)
-				nodeValues.SetValue(k, v)
-				continue
-			}
 			updates = append(updates, firestore.Update{
-				Path:  k,
-				Value: v,
+				FieldPath: firestore.FieldPath(strings.Split(k, ".")),
+				Value:     v,
 			})
 		}
-		if len(updates) > 0 {
-			if _, err = ref.Update(ctx, updates);err != nil {
-				return err
-			}
-		}
-
+		writes = append(writes, &write{ref: ref, updates: updates, preconditions: preconditions})
+	}
+	return writes, nil
+}
 
-		for key := range nodeKeys {
-			absolutePathRef := statement.Table +"/" + ref.ID + "/" + strings.Replace(key, ".", "/", len(key))
-			value, _ := nodeValues.GetValue(key)
-			valueMap := value.(map[string]interface{})
-			if snapshot, err := client.Doc(absolutePathRef).Get(ctx);err == nil && snapshot.Exists() {
-				for k, v := range snapshot.Data() {
-					if _, ok := valueMap[k];!ok {
-						valueMap[k] = v
-					}
+//subDocumentWrites returns legacy writes where dotted columns are stored in sub documents, i.e. table/id/node
+func (m *manager) subDocumentWrites(client *firestore.Client, ctx context.Context, ref *firestore.DocumentRef, record map[string]interface{}, preconditions []firestore.Precondition) []*write {
+	var writes = make([]*write, 0)
+	var nodeValues = data.NewMap()
+	var nodeKeys = make(map[string]bool)
+	if len(record) == 0 {
+		return writes
+	}
+	var updates = make([]firestore.Update, 0)
+	for k, v := range record {
+		if strings.Contains(k, ".") {
+			node := string(k[:strings.LastIndex(k, ".")])
+			nodeKeys[node] = true
+			nodeValues.SetValue(k, v)
+			continue
+		}
+		updates = append(updates, firestore.Update{
+			Path:  k,
+			Value: v,
+		})
+	}
+	if len(updates) > 0 {
+		writes = append(writes, &write{ref: ref, updates: updates, preconditions: preconditions})
+	}
+	for key := range nodeKeys {
+		absolutePathRef := relativePath(ref.Path) + "/" + strings.Replace(key, ".", "/", len(key))
+		value, _ := nodeValues.GetValue(key)
+		valueMap := value.(map[string]interface{})
+		if snapshot, err := getDocument(ctx, client.Doc(absolutePathRef)); err == nil && snapshot.Exists() {
+			for k, v := range snapshot.Data() {
+				if _, ok := valueMap[k]; !ok {
+					valueMap[k] = v
 				}
 			}
-			if _, err = client.Doc(absolutePathRef).Set(ctx, value);err != nil {
-				break
-			}
 		}
+		writes = append(writes, &write{ref: client.Doc(absolutePathRef), record: valueMap})
 	}
-	return err
-}
-
-func (m *manager) criteria(statement *dsc.BaseStatement, parameters toolbox.Iterator) (map[string]interface{}, error) {
-	return asCriteriaMap(statement.SQLCriteria, parameters)
-
+	return writes
 }
 
-func (m *manager) runDelete(client *firestore.Client, ctx context.Context, statement *dsc.DmlStatement, sqlParameters []interface{}) (affected int, err error) {
+//update updates document by key or documents matched by criteria, it returns number of updated documents
+func (m *manager) update(client *firestore.Client, ctx context.Context, statement *dmlStatement, sqlParameters []interface{}) (int, error) {
 	parameters := toolbox.NewSliceIterator(sqlParameters)
-	criteriaMap, err := asCriteriaMap(statement.SQLCriteria, parameters)
+	record, err := statement.ColumnValueMap(parameters)
 	if err != nil {
 		return 0, err
 	}
-	if len(criteriaMap) == 0 {
-		pathRef := statement.Table
-		docRef := client.Doc(pathRef + "/*")
-		_, err := docRef.Delete(ctx)
+	predicate, err := asPredicate(statement.SQLCriteria, parameters)
+	if err != nil {
 		return 0, err
 	}
+	bindings := predicate.bind(pathParameters(statement.Table))
+	matcher := newPathMatcher(statement.Table, bindings)
+	if key, ok := predicate.keyValue(m.getKeyColumn(statement.Table), pathParameters(statement.Table)); ok && !toolbox.IsSlice(key) && matcher == nil {
+		writes, err := m.updateWrites(client, ctx, statement, sqlParameters)
+		if err != nil {
+			return 0, err
+		}
+		return 1, m.applyWrites(client, ctx, writes)
+	}
+	query, _ := m.query(client, statement.Table, bindings)
+	return m.writeMatched(client, ctx, query, predicate, matcher.writer(func(ctx context.Context, document *firestore.DocumentSnapshot) ([][]*write, error) {
+		var documentRecord = make(map[string]interface{})
+		for k, v := range record {
+			documentRecord[k] = v
+		}
+		writes, err := m.documentUpdateWrites(client, ctx, statement, document.Ref, documentRecord)
+		return [][]*write{writes}, err
+	}))
+}
 
-	keyColumn := m.getKeyColumn(statement.Table)
+func (m *manager) criteria(statement *dsc.BaseStatement, parameters toolbox.Iterator) (map[string]interface{}, error) {
+	return asCriteriaMap(statement.SQLCriteria, parameters)
+
+}
+
+func (m *manager) deleteWrites(client *firestore.Client, ctx context.Context, table string, criteriaMap map[string]interface{}) ([]*write, error) {
+	keyColumn := m.getKeyColumn(table)
 	value, ok := criteriaMap[keyColumn]
 	if !ok {
-		return 0, fmt.Errorf("missing value for %v", keyColumn)
+		return nil, fmt.Errorf("missing value for %v", keyColumn)
 	}
 	var ids = []interface{}{
 		value,
@@ -143,10 +313,60 @@ func (m *manager) runDelete(client *firestore.Client, ctx context.Context, state
 	if toolbox.IsSlice(value) {
 		ids = toolbox.AsSlice(value)
 	}
-	var rowCount = 0
+	var writes = make([]*write, 0)
+	pathRef, err := collectionPath(table, criteriaMap)
+	if err != nil {
+		query := client.CollectionGroup(collectionID(table)).Where(keyColumn, "in", ids)
+		err = m.readDocuments(ctx, query, newPathMatcher(table, criteriaMap).filter(func(document *firestore.DocumentSnapshot) (bool, error) {
+			writes = append(writes, &write{ref: document.Ref, delete: true})
+			return true, nil
+		}))
+		return writes, err
+	}
 	for _, id := range ids {
-		_, err :=  client.Collection(statement.Table).Doc(toolbox.AsString(id)).Delete(ctx)
-		if err != nil {
+		writes = append(writes, &write{ref: client.Collection(pathRef).Doc(toolbox.AsString(id)), delete: true})
+	}
+	return writes, nil
+}
+
+func (m *manager) runDelete(client *firestore.Client, ctx context.Context, statement *dsc.DmlStatement, sqlParameters []interface{}) (affected int, err error) {
+	predicate, err := asPredicate(statement.SQLCriteria, toolbox.NewSliceIterator(sqlParameters))
+	if err != nil {
+		return 0, err
+	}
+	if _, ok := predicate.keyValue(m.getKeyColumn(statement.Table), pathParameters(statement.Table)); !ok {
+		bindings := predicate.bind(pathParameters(statement.Table))
+		query, _ := m.query(client, statement.Table, bindings)
+		return m.writeMatched(client, ctx, query.Select(), predicate, newPathMatcher(statement.Table, bindings).writer(m.deleteWriter(statement.Table)))
+	}
+	parameters := toolbox.NewSliceIterator(sqlParameters)
+	criteriaMap, err := asCriteriaMap(statement.SQLCriteria, parameters)
+	if err != nil {
+		return 0, err
+	}
+	writes, err := m.deleteWrites(client, ctx, statement.Table, criteriaMap)
+	if err != nil {
+		return 0, err
+	}
+	if m.isRecursiveDelete(statement.Table) {
+		for _, deleted := range writes {
+			if err = descendants(ctx, deleted.ref, func(ref *firestore.DocumentRef) {
+				writes = append(writes, &write{ref: ref, delete: true})
+			}); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if transaction := asTransaction(ctx); transaction != nil {
+		transaction.add(writes...)
+		return len(writes), nil
+	}
+	if m.config.bulkWrite {
+		return m.writeBulk(client, ctx, asBulkRecords(writes))
+	}
+	var rowCount = 0
+	for _, write := range writes {
+		if err := write.apply(ctx); err != nil {
 			return 0, err
 		}
 		rowCount++
@@ -160,23 +380,38 @@ func (m *manager) ExecuteOnConnection(connection dsc.Connection, sql string, sql
 	if err != nil {
 		return nil, err
 	}
-	parser := dsc.NewDmlParser()
-	statement, err := parser.Parse(sql)
+	statement, err := parseDML(sql)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse %v due to %v", sql, err)
+		return nil, err
+	}
+	if _, err = m.tableDescriptor(client, ctx, statement.Table); err != nil {
+		return nil, err
 	}
 	var affectedRecords = 1
+	var id string
 	switch statement.Type {
 	case "INSERT":
-		err = m.insert(client, ctx, statement, sqlParameters)
+		id, err = m.insert(client, ctx, statement, sqlParameters)
 	case "UPDATE":
-		err = m.update(client, ctx, statement, sqlParameters)
+		affectedRecords, err = m.update(client, ctx, statement, sqlParameters)
 	case "DELETE":
-		affectedRecords, err = m.runDelete(client, ctx, statement, sqlParameters)
+		affectedRecords, err = m.runDelete(client, ctx, statement.DmlStatement, sqlParameters)
 	}
 	if err != nil {
+		if bulkError, ok := err.(*BulkError); ok {
+			return dsc.NewSQLResult(int64(bulkError.Written), 0), bulkError
+		}
+		if conflictError, ok := err.(*ConflictError); ok {
+			return nil, conflictError
+		}
+		if duplicateKeyError, ok := err.(*DuplicateKeyError); ok {
+			return nil, duplicateKeyError
+		}
 		return nil, fmt.Errorf("failed to modify %v, %v", statement.Table, err)
 	}
+	if id != "" {
+		return &InsertResult{Result: dsc.NewSQLResult(int64(affectedRecords), 0), ID: id}, nil
+	}
 	return dsc.NewSQLResult(int64(affectedRecords), 0), nil
 }
 
@@ -204,71 +439,94 @@ func (m *manager) ReadAllOnWithHandlerOnConnection(connection dsc.Connection, SQ
 	if err != nil {
 		return fmt.Errorf("failed to parse statement %v, %v", SQL, err)
 	}
+	SQLParameters, cursor := asCursor(SQLParameters)
 	parameters := toolbox.NewSliceIterator(SQLParameters)
-	criteriaMap, err := m.criteria(statement.BaseStatement, parameters)
+	predicate, err := asPredicate(statement.SQLCriteria, parameters)
 	if err != nil {
 		return err
 	}
+	client, ctx, err := asClient(connection)
+	if err != nil {
+		return err
+	}
+	if _, err = m.tableDescriptor(client, ctx, statement.Table); err != nil {
+		return err
+	}
+	paging, err := newPaging(statement, parameters, cursor)
+	if err != nil {
+		return err
+	}
+	if cursor != nil {
+		cursor.bind(client)
+	}
+	columns := projection(statement)
 	scanner := dsc.NewSQLScanner(statement, m.Config(), nil)
-	if len(criteriaMap) == 1 {
-		client, ctx, err := asClient(connection)
-		if err != nil {
-			return err
+	bindings := predicate.bind(pathParameters(statement.Table))
+	tableQuery, isCollectionGroup := m.query(client, statement.Table, bindings)
+	matcher := newPathMatcher(statement.Table, bindings)
+	var updateTimeColumn = m.getUpdateTimeColumn(statement.Table)
+	var handler = func(document *firestore.DocumentSnapshot) (bool, error) {
+		scanner.Values = trim(document.Data(), columns)
+		if isCollectionGroup {
+			scanner.Values[parentColumn] = parentPath(document)
 		}
-
-		keyColumn := m.getKeyColumn(statement.Table)
-		value, ok := criteriaMap[keyColumn]
-		if !ok {
-			return fmt.Errorf("missing value for %v", keyColumn)
+		if updateTimeColumn != "" {
+			scanner.Values[updateTimeColumn] = document.UpdateTime
 		}
-		var ids = []interface{}{
-			value,
+		return readingHandler(scanner)
+	}
+	aggregates, err := asAggregates(statement)
+	if err != nil {
+		return err
+	}
+	if len(aggregates) > 0 || len(statement.GroupBy) > 0 {
+		query := predicate.apply(tableQuery)
+		if matcher == nil && len(statement.GroupBy) == 0 && len(aggregates) == len(statement.Columns) && isServerSide(aggregates) {
+			if paging.offset > 0 {
+				return nil
+			}
+			return m.readAggregates(ctx, query, aggregates, scanner, readingHandler)
 		}
-		if toolbox.IsSlice(value) {
-			ids = toolbox.AsSlice(value)
+		if cursor != nil {
+			return fmt.Errorf("cursor is not supported with GROUP BY: %v", SQL)
 		}
-		for _, id := range ids {
-			pathRef := statement.Table
-			document := client.Collection(pathRef).Doc(toolbox.AsString(id))
-			scanner.Values = map[string]interface{}{}
-			snapshot, err := document.Get(ctx)
-			if err != nil {
-				if grpc.Code(err) == codes.NotFound {
-					continue
-				}
-				return err
-			}
-			scanner.Values = snapshot.Data()
-			if cont, err := readingHandler(scanner); err != nil || !cont {
-				return err
-			}
+		grouping, err := newGrouping(statement, aggregates, m.config.maxGroups)
+		if err != nil {
+			return err
+		}
+		return m.readGroups(ctx, query, matcher, grouping, paging, scanner, readingHandler)
+	}
+	keyColumn := m.getKeyColumn(statement.Table)
+	if criteria := predicate.criteria(); !isCollectionGroup && cursor == nil && len(criteria) == 1 && criteria[0].isKeyLookup(keyColumn) {
+		pathRef, _ := collectionPath(statement.Table, bindings)
+		if len(paging.orderBy) == 0 {
+			return m.readByKey(client, ctx, pathRef, criteria[0].value, paging.limiter(handler))
 		}
-	} else if len(criteriaMap) == 0 {
-		store, ctx, err := asClient(connection)
+		var documents = make([]*firestore.DocumentSnapshot, 0)
+		err = m.readByKey(client, ctx, pathRef, criteria[0].value, func(document *firestore.DocumentSnapshot) (bool, error) {
+			documents = append(documents, document)
+			return true, nil
+		})
 		if err != nil {
 			return err
 		}
-		iter := store.Collection(statement.Table).Documents(ctx)
-
-		for {
-
-			doc, err := iter.Next()
-			if err != nil {
-				if err == iterator.Done {
-					break
-				}
-				return err
-			}
-			scanner.Values = doc.Data()
-			if cont, err := readingHandler(scanner); err != nil || !cont {
+		sortDocuments(documents, paging.orderBy)
+		handler = paging.limiter(handler)
+		for _, document := range documents {
+			if cont, err := handler(document); err != nil || !cont {
 				return err
 			}
 		}
-
-	} else {
-		return fmt.Errorf("unupported criteria: %v", criteriaMap)
+		return nil
+	}
+	query := selectColumns(tableQuery, columns, paging.orderBy)
+	if matcher != nil {
+		if cursor != nil {
+			return fmt.Errorf("cursor is not supported with unbound path parameters: %v", SQL)
+		}
+		return m.readFiltered(ctx, query, predicate, paging.unbounded(), matcher.filter(paging.limiter(handler)))
 	}
-	return nil
+	return m.readFiltered(ctx, query, predicate, paging, handler)
 }
 
 func newConfig(conf *dsc.Config) (*config, error) {
@@ -276,5 +534,7 @@ func newConfig(conf *dsc.Config) (*config, error) {
 	return &config{
 		Config:        conf,
 		keyColumnName: keyColumnName,
+		maxGroups:     conf.GetInt(maxGroupsKey, defaultGroups),
+		bulkWrite:     conf.GetBoolean(bulkWriteKey, false),
 	}, nil
 }
@@ -3,13 +3,17 @@ package fsc
 import (
 	"errors"
 	"github.com/viant/dsc"
+	"time"
 )
 
 type managerFactory struct{}
 
 func (f *managerFactory) Create(config *dsc.Config) (dsc.Manager, error) {
 	var connectionProvider = newConnectionProvider(config)
-	manager := &manager{}
+	manager := &manager{
+		tables:  newTableRegistry(time.Duration(config.GetInt(descriptorTTLKey, defaultDescriptorTTL)) * time.Second),
+		schemas: newTableRegistry(0),
+	}
 	var self dsc.Manager = manager
 	super := dsc.NewAbstractManager(config, connectionProvider, self)
 	manager.AbstractManager = super
@@ -2,9 +2,11 @@ package fsc_test
 
 import (
 	"fmt"
+	"github.com/adrianwit/fsc"
 	"github.com/stretchr/testify/assert"
 	"github.com/viant/assertly"
 	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
 	"log"
 	"testing"
 )
@@ -42,6 +44,10 @@ func TestManager(t *testing.T) {
 		affected, _ := sqlResult.RowsAffected()
 		assert.EqualValues(t, 1, affected)
 	}
+	_, err = manager.Execute("INSERT INTO users(id, name) VALUES(?, ?)", 0, "Duplicate")
+	assert.True(t, fsc.IsDuplicateKey(err))
+	_, err = manager.Execute("INSERT INTO users(id, name) VALUES(?, ?) ON DUPLICATE KEY UPDATE name = VALUES(name)", 0, "Name 0")
+	assert.Nil(t, err)
 
 	queryCases := []struct {
 		description string
@@ -116,10 +122,125 @@ func TestManager(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "Read records  with in operator, order by and limit",
+			SQL:         "SELECT id, name FROM users WHERE id IN(?, ?, ?) ORDER BY id DESC LIMIT 2",
+			parameters:  []interface{}{0, 1, 2},
+			expect: []*User{
+				{
+					Id:   2,
+					Name: "Name 2",
+				},
+				{
+					Id:   1,
+					Name: "Name 1",
+				},
+			},
+		},
 		{
 			description: "Read records  with !=",
 			SQL:         "SELECT id, name FROM users WHERE id != 0",
-			parameters:  []interface{}{0, 4},
+			expect: []*User{
+				{
+					Id:   1,
+					Name: "Name 1",
+				},
+				{
+					Id:   2,
+					Name: "Name 2",
+				},
+			},
+		},
+		{
+			description: "Read records  with > on non key column",
+			SQL:         "SELECT id, name FROM users WHERE name > ?",
+			parameters:  []interface{}{"Name 0"},
+			expect: []*User{
+				{
+					Id:   1,
+					Name: "Name 1",
+				},
+				{
+					Id:   2,
+					Name: "Name 2",
+				},
+			},
+		},
+		{
+			description: "Read records  with NOT IN",
+			SQL:         "SELECT id, name FROM users WHERE id NOT IN(?, ?)",
+			parameters:  []interface{}{0, 1},
+			expect: []*User{
+				{
+					Id:   2,
+					Name: "Name 2",
+				},
+			},
+		},
+		{
+			description: "Read records  with multiple predicates",
+			SQL:         "SELECT id, name FROM users WHERE id >= ? AND id < ? AND name != ?",
+			parameters:  []interface{}{0, 2, "Name 0"},
+			expect: []*User{
+				{
+					Id:   1,
+					Name: "Name 1",
+				},
+			},
+		},
+		{
+			description: "Read records  with OR",
+			SQL:         "SELECT id, name FROM users WHERE id = ? OR name = ?",
+			parameters:  []interface{}{0, "Name 2"},
+			expect: []*User{
+				{
+					Id:   0,
+					Name: "Name 0",
+				},
+				{
+					Id:   2,
+					Name: "Name 2",
+				},
+			},
+		},
+		{
+			description: "Read records  with nested criteria",
+			SQL:         "SELECT id, name FROM users WHERE name != ? AND (id = ? OR id = ?)",
+			parameters:  []interface{}{"Name 1", 1, 2},
+			expect: []*User{
+				{
+					Id:   2,
+					Name: "Name 2",
+				},
+			},
+		},
+		{
+			description: "Read records  with order by and limit",
+			SQL:         "SELECT id, name FROM users ORDER BY id DESC LIMIT 2",
+			expect: []*User{
+				{
+					Id:   2,
+					Name: "Name 2",
+				},
+				{
+					Id:   1,
+					Name: "Name 1",
+				},
+			},
+		},
+		{
+			description: "Read records  with order by, limit and offset",
+			SQL:         "SELECT id, name FROM users ORDER BY name LIMIT 1 OFFSET 1",
+			expect: []*User{
+				{
+					Id:   1,
+					Name: "Name 1",
+				},
+			},
+		},
+		{
+			description: "Read records  with unsupported operator",
+			SQL:         "SELECT id, name FROM users WHERE name LIKE 'Name%'",
 			hasError:    true,
 		},
 	}
@@ -138,6 +259,50 @@ func TestManager(t *testing.T) {
 		assertly.AssertValues(t, useCase.expect, records, useCase.description)
 	}
 
+	{ //Test aggregation
+		var result = struct {
+			Count int64   `column:"cnt"`
+			Sum   int64   `column:"total"`
+			Avg   float64 `column:"average"`
+		}{}
+		success, err := manager.ReadSingle(&result, "SELECT COUNT(*) AS cnt, SUM(id) AS total, AVG(id) AS average FROM users WHERE id > ?", []interface{}{0}, nil)
+		if assert.Nil(t, err) && assert.True(t, success) {
+			assert.EqualValues(t, 2, result.Count)
+			assert.EqualValues(t, 3, result.Sum)
+			assert.EqualValues(t, 1.5, result.Avg)
+		}
+	}
+
+	{ //Test group by
+		var result = make([]map[string]interface{}, 0)
+		err = manager.ReadAll(&result, "SELECT name, COUNT(*) AS cnt, MAX(id) AS maxId FROM users GROUP BY name ORDER BY name DESC", nil, nil)
+		if assert.Nil(t, err) && assert.Equal(t, 3, len(result)) {
+			assert.EqualValues(t, "Name 2", result[0]["name"])
+			assert.EqualValues(t, 1, result[0]["cnt"])
+			assert.EqualValues(t, 2, result[0]["maxId"])
+		}
+	}
+
+	{ //Test cursor
+		cursor, err := fsc.NewCursor("")
+		if !assert.Nil(t, err) {
+			return
+		}
+		var ids = make([]int, 0)
+		for i := 0; i < 3; i++ {
+			var records = make([]*User, 0)
+			err = manager.ReadAll(&records, "SELECT id, name FROM users ORDER BY name LIMIT 1", []interface{}{cursor}, nil)
+			if !assert.Nil(t, err) || !assert.Equal(t, 1, len(records)) {
+				return
+			}
+			ids = append(ids, records[0].Id)
+			if cursor, err = fsc.NewCursor(cursor.Token()); !assert.Nil(t, err) {
+				return
+			}
+		}
+		assert.EqualValues(t, []int{0, 1, 2}, ids)
+	}
+
 	{ //Test persist
 		var records = []*User{
 			{
@@ -178,3 +343,483 @@ func TestManager(t *testing.T) {
 	}
 
 }
+
+type Order struct {
+	Id     int     `column:"id"`
+	UserId int     `column:"userId"`
+	Amount float64 `column:"amount"`
+}
+
+func TestManager_Subcollection(t *testing.T) {
+	manager, err := newTestManager(t, nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var orders = []*Order{
+		{Id: 1, UserId: 1, Amount: 10.5},
+		{Id: 2, UserId: 1, Amount: 3},
+		{Id: 3, UserId: 2, Amount: 7},
+	}
+	_, _, err = manager.PersistAll(&orders, "users/{userId}/orders", nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var records = make([]*Order, 0)
+	err = manager.ReadAll(&records, "SELECT id, userId, amount FROM users/{userId}/orders WHERE userId = ? ORDER BY id", []interface{}{1}, nil)
+	if assert.Nil(t, err) {
+		assertly.AssertValues(t, orders[:2], records)
+	}
+	var all = make([]map[string]interface{}, 0)
+	err = manager.ReadAll(&all, "SELECT id, amount, _parent FROM users/{userId}/orders WHERE amount > ?", []interface{}{5}, nil)
+	if assert.Nil(t, err) {
+		assert.Equal(t, 2, len(all))
+		for _, record := range all {
+			assert.Contains(t, record["_parent"], "users/")
+		}
+	}
+	sqlResult, err := manager.Execute("UPDATE users/{userId}/orders SET amount = ? WHERE id = ?", 8, 3)
+	if assert.Nil(t, err) {
+		affected, _ := sqlResult.RowsAffected()
+		assert.EqualValues(t, 1, affected)
+	}
+	records = make([]*Order, 0)
+	err = manager.ReadAll(&records, "SELECT id, userId, amount FROM users/{userId}/orders WHERE userId = ?", []interface{}{2}, nil)
+	if assert.Nil(t, err) && assert.Equal(t, 1, len(records)) {
+		assert.EqualValues(t, 8, records[0].Amount)
+	}
+}
+
+type Comment struct {
+	Id     int    `column:"id"`
+	PostId int    `column:"postId"`
+	Text   string `column:"text"`
+}
+
+func TestManager_CollectionGroup(t *testing.T) {
+	manager, err := newTestManager(t, map[string]interface{}{
+		"comments.collectionGroup": "true",
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+	dialect := dsc.GetDatastoreDialect("fsc")
+	_ = dialect.DropTable(manager, "", "posts")
+	defer func() {
+		_ = dialect.DropTable(manager, "", "posts")
+	}()
+	for i := 0; i < 3; i++ {
+		_, err = manager.Execute("INSERT INTO posts/{postId}/comments(id, postId, text) VALUES(?, ?, ?)", i, i%2, fmt.Sprintf("Comment %d", i))
+		if !assert.Nil(t, err) {
+			return
+		}
+	}
+	var all = make([]map[string]interface{}, 0)
+	err = manager.ReadAll(&all, "SELECT id, text, _parent FROM comments", nil, nil)
+	if assert.Nil(t, err) && assert.Equal(t, 3, len(all)) {
+		for _, record := range all {
+			assert.Equal(t, fmt.Sprintf("posts/%v", toolbox.AsInt(record["id"])%2), record["_parent"])
+		}
+	}
+	var records = make([]*Comment, 0)
+	err = manager.ReadAll(&records, "SELECT id, postId, text FROM comments WHERE postId = ? ORDER BY id DESC", []interface{}{0}, nil)
+	if assert.Nil(t, err) {
+		assertly.AssertValues(t, []*Comment{
+			{Id: 2, PostId: 0, Text: "Comment 2"},
+			{Id: 0, PostId: 0, Text: "Comment 0"},
+		}, records)
+	}
+}
+
+func TestManager_Transaction(t *testing.T) {
+	manager, err := newTestManager(t, nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	connection, err := manager.ConnectionProvider().Get()
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer connection.Close()
+	_, _ = manager.Execute("DELETE FROM users WHERE id IN (?, ?)", 10, 11)
+
+	for _, commit := range []bool{false, true} {
+		if !assert.Nil(t, connection.Begin()) {
+			return
+		}
+		var records = make([]*User, 0)
+		err = manager.ReadAllOnConnection(connection, &records, "SELECT id, name FROM users WHERE id = ?", []interface{}{10}, nil)
+		assert.Nil(t, err)
+		_, err = manager.ExecuteOnConnection(connection, "INSERT INTO users(id, name) VALUES(?, ?)", []interface{}{10, "Name 10"})
+		assert.Nil(t, err)
+		var users = []*User{{Id: 11, Name: "Name 11"}}
+		_, _, err = manager.PersistAllOnConnection(connection, &users, "users", nil)
+		assert.Nil(t, err)
+		if commit {
+			assert.Nil(t, connection.Commit())
+		} else {
+			assert.Nil(t, connection.Rollback())
+		}
+		records = make([]*User, 0)
+		err = manager.ReadAll(&records, "SELECT id, name FROM users WHERE id IN (?, ?)", []interface{}{10, 11}, nil)
+		if assert.Nil(t, err) {
+			if commit {
+				assert.Equal(t, 2, len(records))
+			} else {
+				assert.Equal(t, 0, len(records))
+			}
+		}
+	}
+
+	pooled, err := manager.ConnectionProvider().Get()
+	if !assert.Nil(t, err) {
+		return
+	}
+	if !assert.Nil(t, pooled.Begin()) {
+		return
+	}
+	_, err = manager.ExecuteOnConnection(pooled, "INSERT INTO users(id, name) VALUES(?, ?)", []interface{}{12, "Name 12"})
+	assert.Nil(t, err)
+	assert.Nil(t, pooled.Close())
+	var records = make([]*User, 0)
+	err = manager.ReadAll(&records, "SELECT id, name FROM users WHERE id = ?", []interface{}{12}, nil)
+	if assert.Nil(t, err) {
+		assert.Equal(t, 0, len(records))
+	}
+}
+
+func TestManager_OptimisticConcurrency(t *testing.T) {
+	manager, err := newTestManager(t, map[string]interface{}{
+		"users.versionColumn": "version",
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+	_, err = manager.Execute("INSERT INTO users(id, name) VALUES(?, ?)", 20, "Name 20")
+	if !assert.Nil(t, err) {
+		return
+	}
+	_, err = manager.Execute("UPDATE users SET name = ?, version = ? WHERE id = ?", "Name 20a", 1, 20)
+	assert.Nil(t, err)
+	_, err = manager.Execute("UPDATE users SET name = ?, version = ? WHERE id = ?", "Name 20b", 1, 20)
+	assert.True(t, fsc.IsConflict(err))
+	_, err = manager.Execute("INSERT INTO users(id, name) VALUES(?, ?) ON DUPLICATE KEY UPDATE name = VALUES(name)", 20, "Name 20c")
+	assert.NotNil(t, err)
+	_, _ = manager.Execute("DELETE FROM users WHERE id = ?", 20)
+}
+
+type Note struct {
+	Id   string `column:"id"`
+	Text string `column:"text"`
+}
+
+func TestManager_AutoID(t *testing.T) {
+	manager, err := newTestManager(t, map[string]interface{}{
+		"notes.autoID": "true",
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+	result, err := manager.Execute("INSERT INTO notes(text) VALUES(?)", "note 1")
+	if !assert.Nil(t, err) {
+		return
+	}
+	id := fsc.GeneratedID(result)
+	assert.NotEqual(t, "", id)
+	var notes = []*Note{{Text: "note 2"}, {Text: "note 3"}}
+	inserted, _, err := manager.PersistAll(&notes, "notes", nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 2, inserted)
+	var records = make([]*Note, 0)
+	err = manager.ReadAll(&records, "SELECT id, text FROM notes WHERE id IN (?, ?, ?)", []interface{}{id, notes[0].Id, notes[1].Id}, nil)
+	if assert.Nil(t, err) {
+		assert.Equal(t, 3, len(records))
+	}
+}
+
+func TestManager_NestedUpdate(t *testing.T) {
+	manager, err := newTestManager(t, nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	_, _ = manager.Execute("DELETE FROM users WHERE id = ?", 20)
+	_, err = manager.Execute("INSERT INTO users(id, name, address) VALUES(?, ?, ?)", 20, "Name 20", map[string]interface{}{"city": "Warsaw", "zip": "00-001"})
+	if !assert.Nil(t, err) {
+		return
+	}
+	_, err = manager.Execute("UPDATE users SET address.city = ? WHERE id = ?", "Krakow", 20)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var record = make(map[string]interface{})
+	success, err := manager.ReadSingle(&record, "SELECT id, address FROM users WHERE id = ?", []interface{}{20}, nil)
+	if assert.Nil(t, err) && assert.True(t, success) {
+		assert.EqualValues(t, map[string]interface{}{"city": "Krakow", "zip": "00-001"}, record["address"])
+	}
+}
+
+func TestManager_UpdateExpressions(t *testing.T) {
+	manager, err := newTestManager(t, map[string]interface{}{
+		"users.nullAsDelete": "true",
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+	_, _ = manager.Execute("DELETE FROM users WHERE id = ?", 21)
+	_, err = manager.Execute("INSERT INTO users(id, name, counter, tags, legacy) VALUES(?, ?, ?, ?, ?)", 21, "Name 21", 1, []interface{}{"a", "b"}, "x")
+	if !assert.Nil(t, err) {
+		return
+	}
+	_, err = manager.Execute("UPDATE users SET counter = counter + ?, tags = ARRAY_UNION(tags, ?), updated = CURRENT_TIMESTAMP, legacy = NULL WHERE id = ?", 2, []interface{}{"c"}, 21)
+	if !assert.Nil(t, err) {
+		return
+	}
+	_, err = manager.Execute("UPDATE users SET tags = ARRAY_REMOVE(tags, ?) WHERE id = ?", "a", 21)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var record = make(map[string]interface{})
+	success, err := manager.ReadSingle(&record, "SELECT id, counter, tags, updated, legacy FROM users WHERE id = ?", []interface{}{21}, nil)
+	if assert.Nil(t, err) && assert.True(t, success) {
+		assert.EqualValues(t, 3, record["counter"])
+		assert.EqualValues(t, []interface{}{"b", "c"}, record["tags"])
+		assert.NotNil(t, record["updated"])
+		assert.Nil(t, record["legacy"])
+	}
+}
+
+func TestManager_CriteriaUpdate(t *testing.T) {
+	manager, err := newTestManager(t, nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	for i := 0; i < 3; i++ {
+		_, _ = manager.Execute("DELETE FROM users WHERE id = ?", 30+i)
+		_, err = manager.Execute("INSERT INTO users(id, name, score, active) VALUES(?, ?, ?, ?)", 30+i, fmt.Sprintf("Name %d", 30+i), i-3, true)
+		if !assert.Nil(t, err) {
+			return
+		}
+	}
+	for _, transactionLimit := range []string{"500", "1"} {
+		manager, err := newTestManager(t, map[string]interface{}{
+			"transactionLimit": transactionLimit,
+		})
+		if !assert.Nil(t, err) {
+			return
+		}
+		result, err := manager.Execute("UPDATE users SET active = ? WHERE score < ?", transactionLimit == "1", -1)
+		if !assert.Nil(t, err) {
+			return
+		}
+		affected, _ := result.RowsAffected()
+		assert.EqualValues(t, 2, affected)
+		var records = make([]map[string]interface{}, 0)
+		err = manager.ReadAll(&records, "SELECT id, active FROM users WHERE score < ?", []interface{}{-1}, nil)
+		if assert.Nil(t, err) && assert.Equal(t, 2, len(records)) {
+			for _, record := range records {
+				assert.EqualValues(t, transactionLimit == "1", record["active"])
+			}
+		}
+	}
+}
+
+func TestManager_Delete(t *testing.T) {
+	manager, err := newTestManager(t, map[string]interface{}{
+		"accounts.recursiveDelete": "true",
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+	_, _ = manager.Execute("DELETE FROM accounts")
+	for i := 0; i < 4; i++ {
+		_, err = manager.Execute("INSERT INTO accounts(id, balance) VALUES(?, ?)", i, i*10)
+		if !assert.Nil(t, err) {
+			return
+		}
+	}
+	_, err = manager.Execute("INSERT INTO accounts/{accountId}/entries(id, accountId, amount) VALUES(?, ?, ?)", 1, 3, 5)
+	if !assert.Nil(t, err) {
+		return
+	}
+	result, err := manager.Execute("DELETE FROM accounts WHERE balance < ?", 15)
+	if assert.Nil(t, err) {
+		affected, _ := result.RowsAffected()
+		assert.EqualValues(t, 2, affected)
+	}
+	result, err = manager.Execute("DELETE FROM accounts")
+	if assert.Nil(t, err) {
+		affected, _ := result.RowsAffected()
+		assert.EqualValues(t, 3, affected)
+	}
+	var records = make([]map[string]interface{}, 0)
+	err = manager.ReadAll(&records, "SELECT id FROM accounts", nil, nil)
+	if assert.Nil(t, err) {
+		assert.Equal(t, 0, len(records))
+	}
+}
+
+func TestDialect_DropTable(t *testing.T) {
+	manager, err := newTestManager(t, nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	dialect := dsc.GetDatastoreDialect("fsc")
+	_ = dialect.DropTable(manager, "", "projects")
+	_ = dialect.DropTable(manager, "", "teams")
+	defer func() {
+		_ = dialect.DropTable(manager, "", "teams")
+	}()
+	for i := 0; i < 3; i++ {
+		_, err = manager.Execute("INSERT INTO projects/{projectId}/tasks(id, projectId, title) VALUES(?, ?, ?)", i, i, fmt.Sprintf("Task %d", i))
+		if !assert.Nil(t, err) {
+			return
+		}
+	}
+	if !assert.Nil(t, dialect.DropTable(manager, "", "projects")) {
+		return
+	}
+	var records = make([]map[string]interface{}, 0)
+	err = manager.ReadAll(&records, "SELECT id FROM projects/{projectId}/tasks", nil, nil)
+	if assert.Nil(t, err) {
+		assert.Equal(t, 0, len(records))
+	}
+
+	_, err = manager.Execute("INSERT INTO projects/{projectId}/tasks(id, projectId, title) VALUES(?, ?, ?)", 1, 1, "Task 1")
+	assert.Nil(t, err)
+	_, err = manager.Execute("INSERT INTO teams/{teamId}/tasks(id, teamId, title) VALUES(?, ?, ?)", 1, 1, "Task 1")
+	assert.Nil(t, err)
+	if !assert.Nil(t, dialect.DropTable(manager, "", "projects/{projectId}/tasks")) {
+		return
+	}
+	records = make([]map[string]interface{}, 0)
+	err = manager.ReadAll(&records, "SELECT id FROM projects/{projectId}/tasks", nil, nil)
+	if assert.Nil(t, err) {
+		assert.Equal(t, 0, len(records))
+	}
+	records = make([]map[string]interface{}, 0)
+	err = manager.ReadAll(&records, "SELECT id FROM teams/{teamId}/tasks WHERE teamId = ?", []interface{}{1}, nil)
+	if assert.Nil(t, err) {
+		assert.Equal(t, 1, len(records))
+	}
+}
+
+func TestDialect_CreateTable(t *testing.T) {
+	manager, err := newTestManager(t, nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	dialect := dsc.GetDatastoreDialect("fsc")
+	_ = dialect.DropTable(manager, "", "books")
+	err = dialect.CreateTable(manager, "", "books", "isbn string PRIMARY KEY, title string NOT NULL, pages int")
+	if !assert.Nil(t, err) {
+		return
+	}
+	manager, err = newTestManager(t, nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	columns, err := dialect.GetColumns(manager, "", "books")
+	if assert.Nil(t, err) && assert.Equal(t, 3, len(columns)) {
+		assert.Equal(t, "title", columns[1].Name())
+		assert.Equal(t, "string", columns[1].DatabaseTypeName())
+	}
+	assert.Equal(t, "isbn", dialect.GetKeyName(manager, "", "books"))
+	_, err = manager.Execute("INSERT INTO books(isbn, pages) VALUES(?, ?)", "978-0", 100)
+	assert.NotNil(t, err)
+	_, err = manager.Execute("INSERT INTO books(isbn, title, pages) VALUES(?, ?, ?)", "978-0", "Title", 100)
+	assert.Nil(t, err)
+}
+
+func TestDialect_GetColumns(t *testing.T) {
+	manager, err := newTestManager(t, map[string]interface{}{
+		"samples.sampleSize": "2",
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+	dialect := dsc.GetDatastoreDialect("fsc")
+	_ = dialect.DropTable(manager, "", "samples")
+	_, err = manager.Execute("INSERT INTO samples(id, name, score, address) VALUES(?, ?, ?, ?)", 1, "Name 1", 1, map[string]interface{}{"city": "Warsaw"})
+	if !assert.Nil(t, err) {
+		return
+	}
+	_, err = manager.Execute("INSERT INTO samples(id, name, score, address) VALUES(?, ?, ?, ?)", 2, 2, 2.5, map[string]interface{}{"city": "Krakow"})
+	if !assert.Nil(t, err) {
+		return
+	}
+	columns, err := dialect.GetColumns(manager, "", "samples")
+	if !assert.Nil(t, err) {
+		return
+	}
+	var types = make(map[string]string)
+	for _, column := range columns {
+		types[column.Name()] = column.DatabaseTypeName()
+	}
+	assert.EqualValues(t, map[string]string{
+		"id":           "int64",
+		"name":         "int64|string",
+		"score":        "float64",
+		"address.city": "string",
+	}, types)
+}
+
+type Person struct {
+	Id   int    `column:"id"`
+	Name string `column:"name"`
+	Age  int    `column:"age"`
+}
+
+func TestManager_Validation(t *testing.T) {
+	for _, mode := range []string{"reject", "coerce"} {
+		manager, err := newTestManager(t, map[string]interface{}{
+			"people.schema": "id int, name string, age int",
+			"validation":    mode,
+		})
+		if !assert.Nil(t, err) {
+			return
+		}
+		_, _ = manager.Execute("DELETE FROM people WHERE id = ?", 1)
+		_, err = manager.Execute("INSERT INTO people(id, name, age) VALUES(?, ?, ?)", 1, "Name 1", "42")
+		if mode == "reject" {
+			assert.NotNil(t, err, mode)
+			continue
+		}
+		if !assert.Nil(t, err, mode) {
+			return
+		}
+		var record = make(map[string]interface{})
+		success, err := manager.ReadSingle(&record, "SELECT id, age FROM people WHERE id = ?", []interface{}{1}, nil)
+		if assert.Nil(t, err) && assert.True(t, success) {
+			assert.EqualValues(t, int64(42), record["age"])
+		}
+		if !assert.Nil(t, fsc.RegisterSchema(manager, "people", Person{})) {
+			return
+		}
+		_, err = manager.Execute("UPDATE people SET age = ? WHERE id = ?", "43", 1)
+		assert.Nil(t, err)
+	}
+}
+
+func TestDialect_GetTables(t *testing.T) {
+	manager, err := newTestManager(t, map[string]interface{}{
+		"tableDepth": "2",
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+	for i := 0; i < 2; i++ {
+		_, err = manager.Execute("INSERT INTO stores/{storeId}/orders/{orderId}/items(id, storeId, orderId) VALUES(?, ?, ?)", 1, i, i)
+		if !assert.Nil(t, err) {
+			return
+		}
+	}
+	dialect := dsc.GetDatastoreDialect("fsc")
+	tables, err := dialect.GetTables(manager, "")
+	if assert.Nil(t, err) {
+		assert.Contains(t, tables, "stores")
+		assert.Contains(t, tables, "stores/{storeId}/orders")
+		assert.Contains(t, tables, "stores/{storeId}/orders/{orderId}/items")
+	}
+	_ = dialect.DropTable(manager, "", "stores")
+}
@@ -0,0 +1,70 @@
+package fsc
+
+import (
+	"cloud.google.com/go/firestore"
+	"golang.org/x/net/context"
+)
+
+//documentWriter returns writes for a document matched by criteria, writes are grouped by written document
+type documentWriter func(ctx context.Context, document *firestore.DocumentSnapshot) ([][]*write, error)
+
+//readMatched reads documents matched by predicate and passes their grouped writes to handler, it returns number of written documents, with limit reading stops once more than limit documents would be written
+func (m *manager) readMatched(ctx context.Context, query firestore.Query, predicate *predicate, limit int, writer documentWriter, handler func(writes []*write)) (int, error) {
+	var written = 0
+	err := m.readFiltered(ctx, query, predicate, &paging{}, func(document *firestore.DocumentSnapshot) (bool, error) {
+		records, err := writer(ctx, document)
+		if err != nil {
+			return false, err
+		}
+		written += len(records)
+		if limit > 0 && written > limit {
+			return false, nil
+		}
+		for _, writes := range records {
+			handler(writes)
+		}
+		return true, nil
+	})
+	return written, err
+}
+
+//writeMatched writes documents matched by predicate, it returns number of written documents.
+//Writes are buffered in the connection transaction if active, up to transactionLimit documents are written in a new transaction, otherwise documents are written with batches or bulk writer in bulk write mode
+func (m *manager) writeMatched(client *firestore.Client, ctx context.Context, query firestore.Query, predicate *predicate, writer documentWriter) (int, error) {
+	if transaction := asTransaction(ctx); transaction != nil {
+		return m.readMatched(ctx, query, predicate, 0, writer, func(writes []*write) {
+			transaction.add(writes...)
+		})
+	}
+	if limit := m.config.GetInt(transactionLimitKey, maxBatchSize); limit > 0 {
+		transaction := newTransaction()
+		if err := transaction.begin(client, ctx); err != nil {
+			return 0, err
+		}
+		matched, err := m.readMatched(withTransaction(ctx, transaction), query, predicate, limit, writer, func(writes []*write) {
+			transaction.add(writes...)
+		})
+		if err == nil && matched <= limit {
+			return matched, transaction.end(true)
+		}
+		if rollbackErr := transaction.end(false); err == nil {
+			err = rollbackErr
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	if m.config.bulkWrite {
+		bulk := newBulk(client, ctx)
+		if _, err := m.readMatched(ctx, query, predicate, 0, writer, bulk.add); err != nil {
+			written, _ := bulk.close()
+			return written, err
+		}
+		return bulk.close()
+	}
+	batch := newBatch(client, ctx, m.config.GetInt(batchSizeKey, maxBatchSize))
+	if _, err := m.readMatched(ctx, query, predicate, 0, writer, batch.add); err != nil {
+		return batch.written, err
+	}
+	return batch.close()
+}
@@ -0,0 +1,121 @@
+package fsc
+
+import (
+	"cloud.google.com/go/firestore"
+	"fmt"
+	"github.com/viant/toolbox"
+	"golang.org/x/net/context"
+	"regexp"
+	"strings"
+)
+
+var pathParameterExpression = regexp.MustCompile(`\{([^}/]+)\}`)
+
+//pathParameters returns column names used as placeholders in templated table path i.e. users/{userId}/orders
+func pathParameters(table string) []string {
+	var result = make([]string, 0)
+	for _, matched := range pathParameterExpression.FindAllStringSubmatch(table, -1) {
+		result = append(result, matched[1])
+	}
+	return result
+}
+
+//collectionPath returns collection path with placeholders bound from supplied values
+func collectionPath(table string, values map[string]interface{}) (string, error) {
+	var err error
+	result := pathParameterExpression.ReplaceAllStringFunc(table, func(placeholder string) string {
+		column := placeholder[1 : len(placeholder)-1]
+		value, ok := values[column]
+		if !ok || value == nil || toolbox.IsSlice(value) {
+			err = fmt.Errorf("missing value for %v in %v", column, table)
+			return placeholder
+		}
+		return toolbox.AsString(value)
+	})
+	return result, err
+}
+
+//collectionID returns the last collection path segment
+func collectionID(table string) string {
+	return table[strings.LastIndex(table, "/")+1:]
+}
+
+//placeholderName returns path parameter name for a document key of supplied collection, singular collection ID followed by capitalized key column, i.e. users and id give userId
+func placeholderName(collectionID, keyColumn string) string {
+	name := collectionID
+	switch {
+	case len(name) > 3 && strings.HasSuffix(name, "ies"):
+		name = name[:len(name)-3] + "y"
+	case len(name) > 1 && strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss"):
+		name = name[:len(name)-1]
+	}
+	if keyColumn == "" {
+		return name
+	}
+	return name + strings.ToUpper(keyColumn[:1]) + keyColumn[1:]
+}
+
+//pathMatcher matches document paths of templated table queried as collection group, the collection group query also returns documents of unrelated parents
+type pathMatcher struct {
+	expression *regexp.Regexp
+}
+
+//matches returns true if document belongs to templated table, nil matcher matches all documents
+func (m *pathMatcher) matches(document *firestore.DocumentSnapshot) bool {
+	return m == nil || m.expression.MatchString(relativePath(document.Ref.Path))
+}
+
+//filter returns handler skipping documents of unrelated parents
+func (m *pathMatcher) filter(handler documentHandler) documentHandler {
+	if m == nil {
+		return handler
+	}
+	return func(document *firestore.DocumentSnapshot) (bool, error) {
+		if !m.matches(document) {
+			return true, nil
+		}
+		return handler(document)
+	}
+}
+
+//writer returns document writer skipping documents of unrelated parents
+func (m *pathMatcher) writer(writer documentWriter) documentWriter {
+	if m == nil {
+		return writer
+	}
+	return func(ctx context.Context, document *firestore.DocumentSnapshot) ([][]*write, error) {
+		if !m.matches(document) {
+			return nil, nil
+		}
+		return writer(ctx, document)
+	}
+}
+
+//newPathMatcher returns matcher for templated table with unbound placeholder or nil, bound placeholders match their values, slice value matches any of its elements
+func newPathMatcher(table string, bindings map[string]interface{}) *pathMatcher {
+	if _, err := collectionPath(table, bindings); err == nil {
+		return nil
+	}
+	var expression = ""
+	var offset = 0
+	for _, matched := range pathParameterExpression.FindAllStringSubmatchIndex(table, -1) {
+		expression += regexp.QuoteMeta(table[offset:matched[0]])
+		offset = matched[1]
+		value, ok := bindings[table[matched[2]:matched[3]]]
+		if !ok || value == nil {
+			expression += "[^/]+"
+			continue
+		}
+		var values = []interface{}{value}
+		if toolbox.IsSlice(value) {
+			values = toolbox.AsSlice(value)
+		}
+		var alternatives = make([]string, 0)
+		for _, item := range values {
+			alternatives = append(alternatives, regexp.QuoteMeta(toolbox.AsString(item)))
+		}
+		expression += "(?:" + strings.Join(alternatives, "|") + ")"
+	}
+	expression += regexp.QuoteMeta(table[offset:])
+	return &pathMatcher{expression: regexp.MustCompile("^" + expression + "/[^/]+$")}
+}
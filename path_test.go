@@ -0,0 +1,125 @@
+package fsc
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCollectionPath(t *testing.T) {
+	var useCases = []struct {
+		description string
+		table       string
+		values      map[string]interface{}
+		expected    string
+		hasError    bool
+	}{
+		{
+			description: "collection",
+			table:       "users",
+			expected:    "users",
+		},
+		{
+			description: "bound placeholders",
+			table:       "users/{userId}/orders/{orderId}/items",
+			values:      map[string]interface{}{"userId": 1, "orderId": "a"},
+			expected:    "users/1/orders/a/items",
+		},
+		{
+			description: "missing value",
+			table:       "users/{userId}/orders",
+			values:      map[string]interface{}{"id": 1},
+			hasError:    true,
+		},
+		{
+			description: "slice value",
+			table:       "users/{userId}/orders",
+			values:      map[string]interface{}{"userId": []interface{}{1, 2}},
+			hasError:    true,
+		},
+	}
+	for _, useCase := range useCases {
+		actual, err := collectionPath(useCase.table, useCase.values)
+		if useCase.hasError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		if assert.Nil(t, err, useCase.description) {
+			assert.Equal(t, useCase.expected, actual, useCase.description)
+		}
+	}
+	assert.EqualValues(t, []string{"userId", "orderId"}, pathParameters("users/{userId}/orders/{orderId}/items"))
+	assert.Equal(t, "items", collectionID("users/{userId}/orders/{orderId}/items"))
+}
+
+func TestNewPathMatcher(t *testing.T) {
+	assert.Nil(t, newPathMatcher("users", nil))
+	assert.Nil(t, newPathMatcher("users/{userId}/orders", map[string]interface{}{"userId": 1}))
+	var useCases = []struct {
+		description string
+		table       string
+		bindings    map[string]interface{}
+		path        string
+		expected    bool
+	}{
+		{
+			description: "unbound placeholder",
+			table:       "users/{userId}/orders",
+			path:        "users/1/orders/10",
+			expected:    true,
+		},
+		{
+			description: "unrelated parent",
+			table:       "users/{userId}/orders",
+			path:        "stores/1/orders/10",
+		},
+		{
+			description: "nested collection",
+			table:       "users/{userId}/orders",
+			path:        "accounts/1/users/1/orders/10",
+		},
+		{
+			description: "bound placeholder",
+			table:       "users/{userId}/orders/{orderId}/items",
+			bindings:    map[string]interface{}{"orderId": "a.b"},
+			path:        "users/1/orders/a.b/items/1",
+			expected:    true,
+		},
+		{
+			description: "bound placeholder mismatch",
+			table:       "users/{userId}/orders/{orderId}/items",
+			bindings:    map[string]interface{}{"orderId": "a.b"},
+			path:        "users/1/orders/axb/items/1",
+		},
+		{
+			description: "slice binding",
+			table:       "users/{userId}/orders/{orderId}/items",
+			bindings:    map[string]interface{}{"userId": []interface{}{1, 2}},
+			path:        "users/2/orders/3/items/1",
+			expected:    true,
+		},
+	}
+	for _, useCase := range useCases {
+		matcher := newPathMatcher(useCase.table, useCase.bindings)
+		if !assert.NotNil(t, matcher, useCase.description) {
+			continue
+		}
+		assert.Equal(t, useCase.expected, matcher.expression.MatchString(useCase.path), useCase.description)
+	}
+}
+
+func TestPlaceholderName(t *testing.T) {
+	var useCases = []struct {
+		description  string
+		collectionID string
+		keyColumn    string
+		expected     string
+	}{
+		{description: "plural collection", collectionID: "users", keyColumn: "id", expected: "userId"},
+		{description: "ies plural collection", collectionID: "categories", keyColumn: "id", expected: "categoryId"},
+		{description: "singular collection", collectionID: "address", keyColumn: "code", expected: "addressCode"},
+		{description: "empty key column", collectionID: "orders", expected: "order"},
+	}
+	for _, useCase := range useCases {
+		assert.Equal(t, useCase.expected, placeholderName(useCase.collectionID, useCase.keyColumn), useCase.description)
+	}
+}
@@ -0,0 +1,321 @@
+package fsc
+
+import (
+	"cloud.google.com/go/firestore"
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"golang.org/x/net/context"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"sort"
+	"strings"
+)
+
+const documentsPathFragment = "/documents/"
+
+//documentHandler represents a document snapshot handler
+type documentHandler func(document *firestore.DocumentSnapshot) (toContinue bool, err error)
+
+//paging represents ORDER BY, OFFSET and LIMIT clauses
+type paging struct {
+	orderBy []*dsc.SQLColumn
+	offset  int
+	limit   int
+	cursor  *Cursor
+}
+
+//apply applies paging to supplied query
+func (p *paging) apply(query firestore.Query) firestore.Query {
+	query = p.order(query)
+	if p.cursor != nil {
+		query = p.cursor.apply(query, p.orderBy)
+	}
+	if p.offset > 0 {
+		query = query.Offset(p.offset)
+	}
+	if p.limit > 0 {
+		if p.cursor != nil && p.cursor.reverse {
+			return query.LimitToLast(p.limit)
+		}
+		query = query.Limit(p.limit)
+	}
+	return query
+}
+
+//unbounded returns paging without OFFSET and LIMIT, used when documents are limited on the client side
+func (p *paging) unbounded() *paging {
+	return &paging{orderBy: p.orderBy}
+}
+
+//track returns handler advancing cursor if used
+func (p *paging) track(handler documentHandler) documentHandler {
+	if p.cursor == nil {
+		return handler
+	}
+	return p.cursor.track(p.orderBy, handler)
+}
+
+//order applies ORDER BY to supplied query
+func (p *paging) order(query firestore.Query) firestore.Query {
+	for _, column := range p.orderBy {
+		var direction = firestore.Asc
+		if column.IsDesc {
+			direction = firestore.Desc
+		}
+		query = query.OrderBy(column.Name, direction)
+	}
+	return query
+}
+
+//limiter returns handler applying OFFSET and LIMIT on the client side
+func (p *paging) limiter(handler documentHandler) documentHandler {
+	if p.offset == 0 && p.limit == 0 {
+		return handler
+	}
+	var skipped, read = 0, 0
+	return func(document *firestore.DocumentSnapshot) (bool, error) {
+		if skipped < p.offset {
+			skipped++
+			return true, nil
+		}
+		read++
+		cont, err := handler(document)
+		return cont && (p.limit == 0 || read < p.limit), err
+	}
+}
+
+//asPagingValue returns OFFSET or LIMIT value, a bind parameter is consumed for placeholder
+func asPagingValue(clause, literal string, paramIterator toolbox.Iterator) (int, error) {
+	literal = strings.TrimSpace(literal)
+	if literal == "" {
+		return 0, nil
+	}
+	var value interface{} = literal
+	if literal == "?" {
+		if !paramIterator.HasNext() {
+			return 0, fmt.Errorf("missing bind param: %v %v", clause, literal)
+		}
+		if err := paramIterator.Next(&value); err != nil {
+			return 0, err
+		}
+	}
+	result, err := toolbox.ToInt(value)
+	if err != nil || result < 0 {
+		return 0, fmt.Errorf("invalid %v value: %v", clause, value)
+	}
+	return result, nil
+}
+
+func newPaging(statement *dsc.QueryStatement, paramIterator toolbox.Iterator, cursor *Cursor) (*paging, error) {
+	var result = &paging{orderBy: statement.OrderBy, cursor: cursor}
+	var err error
+	if result.limit, err = asPagingValue("LIMIT", statement.Limit, paramIterator); err != nil {
+		return nil, err
+	}
+	if result.offset, err = asPagingValue("OFFSET", statement.Offset, paramIterator); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+//relativePath returns document path relative to database documents root
+func relativePath(path string) string {
+	if index := strings.Index(path, documentsPathFragment); index != -1 {
+		return path[index+len(documentsPathFragment):]
+	}
+	return path
+}
+
+//parentPath returns parent document path or empty string for root collection document
+func parentPath(document *firestore.DocumentSnapshot) string {
+	if document.Ref.Parent == nil || document.Ref.Parent.Parent == nil {
+		return ""
+	}
+	return relativePath(document.Ref.Parent.Parent.Path)
+}
+
+//projection returns selected column names, empty projection represents all fields
+func projection(statement *dsc.QueryStatement) []string {
+	var result = make([]string, 0)
+	for _, column := range statement.Columns {
+		if column.Name == "*" {
+			return []string{}
+		}
+		result = append(result, column.Name)
+	}
+	return result
+}
+
+//selectColumns returns query returning only projected and ordering fields
+func selectColumns(query firestore.Query, columns []string, orderBy []*dsc.SQLColumn) firestore.Query {
+	if len(columns) == 0 {
+		return query
+	}
+	var paths = append([]string{}, columns...)
+	var selected = make(map[string]bool)
+	for _, column := range columns {
+		selected[column] = true
+	}
+	for _, column := range orderBy {
+		if !selected[column.Name] {
+			paths = append(paths, column.Name)
+		}
+	}
+	return query.Select(paths...)
+}
+
+//trim returns record with projected fields only, nested field selects its top level field
+func trim(record map[string]interface{}, columns []string) map[string]interface{} {
+	if len(columns) == 0 {
+		return record
+	}
+	var result = make(map[string]interface{})
+	for _, column := range columns {
+		field := column
+		if index := strings.Index(column, "."); index != -1 {
+			field = column[:index]
+		}
+		if value, ok := record[field]; ok {
+			result[field] = value
+		}
+	}
+	return result
+}
+
+func (m *manager) readByKey(client *firestore.Client, ctx context.Context, table string, value interface{}, handler documentHandler) error {
+	var ids = []interface{}{
+		value,
+	}
+	if toolbox.IsSlice(value) {
+		ids = toolbox.AsSlice(value)
+	}
+	for _, id := range ids {
+		document := client.Collection(table).Doc(toolbox.AsString(id))
+		snapshot, err := getDocument(ctx, document)
+		if err != nil {
+			if grpc.Code(err) == codes.NotFound {
+				continue
+			}
+			return err
+		}
+		if cont, err := handler(snapshot); err != nil || !cont {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *manager) readDocuments(ctx context.Context, query firestore.Query, handler documentHandler) error {
+	iter := query.Documents(ctx)
+	if transaction := asTransaction(ctx); transaction != nil {
+		iter = transaction.tx.Documents(query)
+	}
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err != nil {
+			if err == iterator.Done {
+				break
+			}
+			return err
+		}
+		if cont, err := handler(doc); err != nil || !cont {
+			return err
+		}
+	}
+	return nil
+}
+
+//readUnion runs each conjunction as separate query, documents are de-duplicated by path, OFFSET and LIMIT are applied on the client side, with ORDER BY conjunction results are merged and sorted before paging
+func (m *manager) readUnion(ctx context.Context, query firestore.Query, predicate *predicate, paging *paging, handler documentHandler) error {
+	if paging.cursor != nil {
+		return fmt.Errorf("cursor is not supported with criteria: %v", predicate.filter())
+	}
+	var seen = make(map[string]bool)
+	var stopped = false
+	var documents = make([]*firestore.DocumentSnapshot, 0)
+	var ordered = len(paging.orderBy) > 0
+	handler = paging.limiter(handler)
+	for _, conjunction := range predicate.disjunctions() {
+		conjunctionQuery := paging.order(query)
+		if ordered && paging.limit > 0 {
+			conjunctionQuery = conjunctionQuery.Limit(paging.offset + paging.limit)
+		}
+		for _, criterion := range conjunction {
+			conjunctionQuery = conjunctionQuery.Where(criterion.column, criterion.operator, criterion.whereValue())
+		}
+		err := m.readDocuments(ctx, conjunctionQuery, func(document *firestore.DocumentSnapshot) (bool, error) {
+			if seen[document.Ref.Path] {
+				return true, nil
+			}
+			seen[document.Ref.Path] = true
+			if ordered {
+				documents = append(documents, document)
+				return true, nil
+			}
+			cont, err := handler(document)
+			stopped = !cont
+			return cont, err
+		})
+		if err != nil || stopped {
+			return err
+		}
+	}
+	if !ordered {
+		return nil
+	}
+	sortDocuments(documents, paging.orderBy)
+	for _, document := range documents {
+		if cont, err := handler(document); err != nil || !cont {
+			return err
+		}
+	}
+	return nil
+}
+
+//sortDocuments sorts documents by supplied ORDER BY columns
+func sortDocuments(documents []*firestore.DocumentSnapshot, orderBy []*dsc.SQLColumn) {
+	sort.SliceStable(documents, func(i, j int) bool {
+		for _, column := range orderBy {
+			left, _ := documents[i].DataAt(column.Name)
+			right, _ := documents[j].DataAt(column.Name)
+			comparison := compareValues(left, right)
+			if comparison == 0 {
+				continue
+			}
+			if column.IsDesc {
+				return comparison > 0
+			}
+			return comparison < 0
+		}
+		return false
+	})
+}
+
+//readFiltered reads documents matching predicate, if firestore can not express predicate it falls back to client side union
+func (m *manager) readFiltered(ctx context.Context, query firestore.Query, predicate *predicate, paging *paging, handler documentHandler) error {
+	handler = paging.track(handler)
+	if predicate.isConjunction() {
+		return m.readDocuments(ctx, paging.apply(predicate.apply(query)), handler)
+	}
+	var read = 0
+	err := m.readDocuments(ctx, paging.apply(predicate.apply(query)), func(document *firestore.DocumentSnapshot) (bool, error) {
+		read++
+		return handler(document)
+	})
+	if err != nil && read == 0 && isUnsupportedQuery(err) {
+		return m.readUnion(ctx, query, predicate, paging, handler)
+	}
+	return err
+}
+
+//isUnsupportedQuery returns true if error indicates that firestore can not run a query
+func isUnsupportedQuery(err error) bool {
+	switch grpc.Code(err) {
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.Unimplemented:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package fsc
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"testing"
+)
+
+func TestProjection(t *testing.T) {
+	var useCases = []struct {
+		description string
+		columns     []string
+		expected    []string
+	}{
+		{description: "all fields", columns: []string{"*"}, expected: []string{}},
+		{description: "columns", columns: []string{"id", "name"}, expected: []string{"id", "name"}},
+		{description: "nested column", columns: []string{"id", "address.city"}, expected: []string{"id", "address.city"}},
+	}
+	for _, useCase := range useCases {
+		var statement = &dsc.QueryStatement{Columns: make([]*dsc.SQLColumn, 0)}
+		for _, column := range useCase.columns {
+			statement.Columns = append(statement.Columns, &dsc.SQLColumn{Name: column})
+		}
+		assert.Equal(t, useCase.expected, projection(statement), useCase.description)
+	}
+}
+
+func TestSelectColumns(t *testing.T) {
+	client, _, closer := newUnreachableClient(t)
+	defer closer()
+	query := client.Collection("users").Query
+	var useCases = []struct {
+		description string
+		columns     []string
+		orderBy     []*dsc.SQLColumn
+		expected    []string
+	}{
+		{description: "all fields", orderBy: []*dsc.SQLColumn{{Name: "name"}}},
+		{description: "columns", columns: []string{"id", "name"}, expected: []string{"id", "name"}},
+		{description: "order by column selected", columns: []string{"id", "name"}, orderBy: []*dsc.SQLColumn{{Name: "name"}}, expected: []string{"id", "name"}},
+		{description: "order by column added", columns: []string{"id"}, orderBy: []*dsc.SQLColumn{{Name: "name", IsDesc: true}}, expected: []string{"id", "name"}},
+	}
+	for _, useCase := range useCases {
+		expected := query
+		if len(useCase.expected) > 0 {
+			expected = query.Select(useCase.expected...)
+		}
+		assert.Equal(t, expected, selectColumns(query, useCase.columns, useCase.orderBy), useCase.description)
+	}
+}
+
+func TestTrim(t *testing.T) {
+	var record = map[string]interface{}{
+		"id":      1,
+		"name":    "Name 1",
+		"address": map[string]interface{}{"city": "Warsaw", "zip": "00-001"},
+	}
+	var useCases = []struct {
+		description string
+		columns     []string
+		expected    map[string]interface{}
+	}{
+		{description: "all fields", columns: []string{}, expected: record},
+		{description: "document get returns all fields", columns: []string{"id"}, expected: map[string]interface{}{"id": 1}},
+		{description: "nested column keeps top level field", columns: []string{"id", "address.city"}, expected: map[string]interface{}{"id": 1, "address": record["address"]}},
+		{description: "missing field", columns: []string{"id", "email"}, expected: map[string]interface{}{"id": 1}},
+	}
+	for _, useCase := range useCases {
+		assert.Equal(t, useCase.expected, trim(record, useCase.columns), useCase.description)
+	}
+}
@@ -0,0 +1,260 @@
+package fsc
+
+import (
+	"cloud.google.com/go/firestore"
+	"fmt"
+	"golang.org/x/net/context"
+	"google.golang.org/genproto/googleapis/type/latlng"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+//TableDescriptor represents table schema persisted in the metadata collection
+type TableDescriptor struct {
+	Table     string              `firestore:"table"`
+	KeyColumn string              `firestore:"keyColumn"`
+	Columns   []*ColumnDescriptor `firestore:"columns"`
+}
+
+//ColumnDescriptor represents table column schema
+type ColumnDescriptor struct {
+	Name     string `firestore:"name"`
+	Type     string `firestore:"type"`
+	Required bool   `firestore:"required"`
+}
+
+//Column returns column descriptor or nil
+func (d *TableDescriptor) Column(name string) *ColumnDescriptor {
+	for _, column := range d.Columns {
+		if column.Name == name {
+			return column
+		}
+	}
+	return nil
+}
+
+//checkRequired returns an error if a required column is missing or null, with partial record only supplied columns are checked
+func (d *TableDescriptor) checkRequired(record map[string]interface{}, partial bool) error {
+	for _, column := range d.Columns {
+		if !column.Required {
+			continue
+		}
+		value, ok := record[column.Name]
+		if !ok && partial {
+			continue
+		}
+		if !ok || value == nil || value == firestore.Delete {
+			return fmt.Errorf("missing value for required column %v.%v", d.Table, column.Name)
+		}
+	}
+	return nil
+}
+
+//asTableDescriptor returns table descriptor for supplied CreateTable specification, either *TableDescriptor or column definitions, i.e. "id string PRIMARY KEY, name string NOT NULL, age int"
+func asTableDescriptor(table string, specification interface{}) (*TableDescriptor, error) {
+	var result *TableDescriptor
+	switch actual := specification.(type) {
+	case *TableDescriptor:
+		result = actual
+	case TableDescriptor:
+		result = &actual
+	case string:
+		result = &TableDescriptor{Columns: make([]*ColumnDescriptor, 0)}
+		for _, definition := range strings.Split(actual, ",") {
+			fragments := strings.Fields(definition)
+			if len(fragments) == 0 {
+				continue
+			}
+			var column = &ColumnDescriptor{Name: fragments[0]}
+			if len(fragments) > 1 {
+				column.Type = strings.ToLower(fragments[1])
+			}
+			options := strings.ToUpper(strings.Join(fragments[1:], " "))
+			if strings.Contains(options, "PRIMARY KEY") {
+				result.KeyColumn = column.Name
+				column.Required = true
+			}
+			if strings.Contains(options, "NOT NULL") {
+				column.Required = true
+			}
+			result.Columns = append(result.Columns, column)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported table specification: %T", specification)
+	}
+	result.Table = table
+	return result, nil
+}
+
+//tableRegistry represents table descriptors cache, a table without descriptor is cached till the miss expires, thus descriptors created by other processes are eventually loaded
+type tableRegistry struct {
+	mutex  *sync.RWMutex
+	tables map[string]*TableDescriptor
+	misses map[string]time.Time
+	ttl    time.Duration
+}
+
+//get returns cached descriptor, nil descriptor with true represents not expired miss
+func (r *tableRegistry) get(table string) (*TableDescriptor, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if result, ok := r.tables[table]; ok {
+		return result, true
+	}
+	expiry, ok := r.misses[table]
+	return nil, ok && time.Now().Before(expiry)
+}
+
+//put caches descriptor, nil descriptor is cached as a miss
+func (r *tableRegistry) put(table string, descriptor *TableDescriptor) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if descriptor == nil {
+		delete(r.tables, table)
+		r.misses[table] = time.Now().Add(r.ttl)
+		return
+	}
+	delete(r.misses, table)
+	r.tables[table] = descriptor
+}
+
+func newTableRegistry(ttl time.Duration) *tableRegistry {
+	return &tableRegistry{
+		mutex:  &sync.RWMutex{},
+		tables: make(map[string]*TableDescriptor),
+		misses: make(map[string]time.Time),
+		ttl:    ttl,
+	}
+}
+
+//descriptorRef returns metadata collection document reference for supplied table
+func (m *manager) descriptorRef(client *firestore.Client, table string) *firestore.DocumentRef {
+	collection := m.config.GetString(metadataCollectionKey, defaultMetadataCollection)
+	return client.Collection(collection).Doc(url.PathEscape(table))
+}
+
+//tableDescriptor returns table descriptor or nil if the table has no descriptor, descriptors are loaded once from the metadata collection, a missing descriptor is looked up again once descriptorTTL elapses
+func (m *manager) tableDescriptor(client *firestore.Client, ctx context.Context, table string) (*TableDescriptor, error) {
+	if result, ok := m.tables.get(table); ok {
+		return result, nil
+	}
+	snapshot, err := m.descriptorRef(client, table).Get(ctx)
+	if err != nil {
+		if grpc.Code(err) == codes.NotFound {
+			m.tables.put(table, nil)
+			return nil, nil
+		}
+		return nil, err
+	}
+	var result = &TableDescriptor{}
+	if err = snapshot.DataTo(result); err != nil {
+		return nil, fmt.Errorf("invalid %v table descriptor: %v", table, err)
+	}
+	m.tables.put(table, result)
+	return result, nil
+}
+
+//createTable persists table descriptor in the metadata collection
+func (m *manager) createTable(client *firestore.Client, ctx context.Context, descriptor *TableDescriptor) error {
+	if _, err := m.descriptorRef(client, descriptor.Table).Set(ctx, descriptor); err != nil {
+		return err
+	}
+	m.tables.put(descriptor.Table, descriptor)
+	return nil
+}
+
+//dropTableDescriptor removes table descriptor from the metadata collection
+func (m *manager) dropTableDescriptor(client *firestore.Client, ctx context.Context, table string) error {
+	if _, err := m.descriptorRef(client, table).Delete(ctx); err != nil {
+		return err
+	}
+	m.tables.put(table, nil)
+	return nil
+}
+
+//columnInference represents column types inferred from document values
+type columnInference struct {
+	columns []string
+	types   map[string]map[string]bool
+}
+
+//add adds document values, nested map fields are added as dotted columns
+func (i *columnInference) add(prefix string, values map[string]interface{}) {
+	var keys = make([]string, 0)
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		column := prefix + key
+		value := values[key]
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			i.add(column+".", nested)
+			continue
+		}
+		types, ok := i.types[column]
+		if !ok {
+			types = make(map[string]bool)
+			i.types[column] = types
+			i.columns = append(i.columns, column)
+		}
+		if typeName := asTypeName(value); typeName != "" {
+			types[typeName] = true
+		}
+	}
+}
+
+//typeName returns column type, int64 and float64 values are reported as float64, conflicting types are reported joined with |
+func (i *columnInference) typeName(column string) string {
+	types := i.types[column]
+	if types["int64"] && types["float64"] {
+		delete(types, "int64")
+	}
+	var result = make([]string, 0)
+	for typeName := range types {
+		result = append(result, typeName)
+	}
+	sort.Strings(result)
+	return strings.Join(result, "|")
+}
+
+func newColumnInference() *columnInference {
+	return &columnInference{
+		columns: make([]string, 0),
+		types:   make(map[string]map[string]bool),
+	}
+}
+
+//asTypeName returns column type name for supplied document value, null value has no type
+func asTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return ""
+	case string:
+		return "string"
+	case int64:
+		return "int64"
+	case float64:
+		return "float64"
+	case bool:
+		return "bool"
+	case time.Time:
+		return "timestamp"
+	case []byte:
+		return "bytes"
+	case *latlng.LatLng:
+		return "geopoint"
+	case *firestore.DocumentRef:
+		return "reference"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "map"
+	}
+	return fmt.Sprintf("%T", value)
+}
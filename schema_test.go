@@ -0,0 +1,79 @@
+package fsc
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestAsTableDescriptor(t *testing.T) {
+	descriptor, err := asTableDescriptor("books", "isbn string PRIMARY KEY, title String NOT NULL, pages int, ")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "books", descriptor.Table)
+	assert.Equal(t, "isbn", descriptor.KeyColumn)
+	assert.EqualValues(t, []*ColumnDescriptor{
+		{Name: "isbn", Type: "string", Required: true},
+		{Name: "title", Type: "string", Required: true},
+		{Name: "pages", Type: "int"},
+	}, descriptor.Columns)
+	assert.NotNil(t, descriptor.checkRequired(map[string]interface{}{"isbn": "1"}, false))
+	assert.Nil(t, descriptor.checkRequired(map[string]interface{}{"pages": 10}, true))
+
+	descriptor, err = asTableDescriptor("authors", TableDescriptor{KeyColumn: "name"})
+	if assert.Nil(t, err) {
+		assert.Equal(t, "authors", descriptor.Table)
+		assert.Equal(t, "name", descriptor.KeyColumn)
+	}
+	_, err = asTableDescriptor("authors", 1)
+	assert.NotNil(t, err)
+}
+
+func TestColumnInference(t *testing.T) {
+	inference := newColumnInference()
+	inference.add("", map[string]interface{}{
+		"id":      int64(1),
+		"name":    "Name 1",
+		"score":   int64(1),
+		"address": map[string]interface{}{"city": "Warsaw"},
+		"note":    nil,
+	})
+	inference.add("", map[string]interface{}{
+		"id":       int64(2),
+		"name":     int64(2),
+		"score":    2.5,
+		"modified": time.Now(),
+	})
+	assert.EqualValues(t, []string{"address.city", "id", "name", "note", "score", "modified"}, inference.columns)
+	var expected = map[string]string{
+		"address.city": "string",
+		"id":           "int64",
+		"name":         "int64|string",
+		"note":         "",
+		"score":        "float64",
+		"modified":     "timestamp",
+	}
+	for column, typeName := range expected {
+		assert.Equal(t, typeName, inference.typeName(column), column)
+	}
+}
+
+func TestTableRegistry(t *testing.T) {
+	registry := newTableRegistry(time.Hour)
+	_, ok := registry.get("books")
+	assert.False(t, ok)
+	registry.put("books", nil)
+	descriptor, ok := registry.get("books")
+	assert.True(t, ok)
+	assert.Nil(t, descriptor)
+	registry.put("books", &TableDescriptor{Table: "books"})
+	descriptor, ok = registry.get("books")
+	assert.True(t, ok)
+	assert.NotNil(t, descriptor)
+
+	registry = newTableRegistry(0)
+	registry.put("books", nil)
+	_, ok = registry.get("books")
+	assert.False(t, ok)
+}
@@ -0,0 +1,101 @@
+package fsc
+
+import (
+	"cloud.google.com/go/firestore"
+	"errors"
+	"golang.org/x/net/context"
+)
+
+var errRollback = errors.New("transaction was rolled back")
+
+type transactionKey struct{}
+
+//transaction represents firestore transaction controlled with connection Begin, Commit and Rollback, reads run in the transaction, writes are buffered till commit
+type transaction struct {
+	tx      *firestore.Transaction
+	writes  []*write
+	started chan *firestore.Transaction
+	finish  chan bool
+	done    chan error
+}
+
+//add buffers writes till commit
+func (t *transaction) add(writes ...*write) {
+	t.writes = append(t.writes, writes...)
+}
+
+//run runs firestore transaction function, it waits for commit or rollback
+func (t *transaction) run(ctx context.Context, tx *firestore.Transaction) error {
+	t.started <- tx
+	if commit := <-t.finish; !commit {
+		return errRollback
+	}
+	for _, write := range t.writes {
+		var err error
+		switch {
+		case write.delete:
+			err = tx.Delete(write.ref, write.preconditions...)
+		case len(write.updates) > 0:
+			err = tx.Update(write.ref, write.updates, write.preconditions...)
+		case write.create:
+			err = tx.Create(write.ref, write.record)
+		default:
+			err = tx.Set(write.ref, write.record, write.setOptions()...)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//begin starts firestore transaction, the transaction is attempted once since reads can not be replayed
+func (t *transaction) begin(client *firestore.Client, ctx context.Context) error {
+	go func() {
+		t.done <- client.RunTransaction(ctx, t.run, firestore.MaxAttempts(1))
+	}()
+	select {
+	case t.tx = <-t.started:
+		return nil
+	case err := <-t.done:
+		return err
+	}
+}
+
+//end commits or rolls back the transaction
+func (t *transaction) end(commit bool) error {
+	t.finish <- commit
+	err := <-t.done
+	if err == errRollback {
+		return nil
+	}
+	return asWriteError("transaction", err)
+}
+
+func newTransaction() *transaction {
+	return &transaction{
+		writes:  make([]*write, 0),
+		started: make(chan *firestore.Transaction, 1),
+		finish:  make(chan bool, 1),
+		done:    make(chan error, 1),
+	}
+}
+
+//withTransaction returns context with the transaction
+func withTransaction(ctx context.Context, transaction *transaction) context.Context {
+	return context.WithValue(ctx, transactionKey{}, transaction)
+}
+
+//asTransaction returns active transaction or nil
+func asTransaction(ctx context.Context) *transaction {
+	result, _ := ctx.Value(transactionKey{}).(*transaction)
+	return result
+}
+
+//getDocument returns document snapshot, the document is read in a transaction if active
+func getDocument(ctx context.Context, ref *firestore.DocumentRef) (*firestore.DocumentSnapshot, error) {
+	if transaction := asTransaction(ctx); transaction != nil {
+		return transaction.tx.Get(ref)
+	}
+	return ref.Get(ctx)
+}
@@ -0,0 +1,208 @@
+package fsc
+
+import (
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	validationCoerce = "coerce"
+	validationWarn   = "warn"
+)
+
+//schemaTypes maps declared column types to document value types
+var schemaTypes = map[string]string{
+	"string":    "string",
+	"text":      "string",
+	"varchar":   "string",
+	"int":       "int64",
+	"int64":     "int64",
+	"integer":   "int64",
+	"bigint":    "int64",
+	"float":     "float64",
+	"float64":   "float64",
+	"double":    "float64",
+	"numeric":   "float64",
+	"decimal":   "float64",
+	"bool":      "bool",
+	"boolean":   "bool",
+	"timestamp": "timestamp",
+	"time":      "timestamp",
+	"datetime":  "timestamp",
+	"date":      "timestamp",
+	"bytes":     "bytes",
+	"array":     "array",
+	"map":       "map",
+	"geopoint":  "geopoint",
+	"reference": "reference",
+}
+
+//RegisterSchema registers table schema declared with supplied struct, columns are matched by column tag or field name, the schema is used only for validation and takes precedence over the table descriptor
+func RegisterSchema(candidate dsc.Manager, table string, prototype interface{}) error {
+	manager, err := asManager(candidate)
+	if err != nil {
+		return err
+	}
+	structType := reflect.TypeOf(prototype)
+	for structType != nil && structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType == nil || structType.Kind() != reflect.Struct {
+		return fmt.Errorf("expected struct but had: %T", prototype)
+	}
+	var descriptor = &TableDescriptor{Table: table, Columns: make([]*ColumnDescriptor, 0)}
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		column := field.Tag.Get("column")
+		if column == "-" {
+			continue
+		}
+		if column == "" {
+			column = field.Name
+		}
+		descriptor.Columns = append(descriptor.Columns, &ColumnDescriptor{Name: column, Type: asSchemaType(field.Type)})
+	}
+	manager.schemas.put(table, descriptor)
+	return nil
+}
+
+//asSchemaType returns schema type for supplied go type
+func asSchemaType(fieldType reflect.Type) string {
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	if fieldType == reflect.TypeOf(time.Time{}) {
+		return "timestamp"
+	}
+	switch fieldType.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "int64"
+	case reflect.Float32, reflect.Float64:
+		return "float64"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Slice, reflect.Array:
+		if fieldType.Elem().Kind() == reflect.Uint8 {
+			return "bytes"
+		}
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "map"
+	}
+	return ""
+}
+
+//isSchemaType returns true if value matches supplied schema type
+func isSchemaType(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case "timestamp":
+		switch value.(type) {
+		case time.Time, *time.Time:
+			return true
+		}
+		return false
+	case "geopoint", "reference":
+		return asTypeName(value) == schemaType
+	}
+	valueType := reflect.TypeOf(value)
+	for valueType.Kind() == reflect.Ptr {
+		valueType = valueType.Elem()
+	}
+	var actual = asSchemaType(valueType)
+	return actual == schemaType || (schemaType == "float64" && actual == "int64")
+}
+
+//coerce converts value to supplied schema type
+func coerce(schemaType string, value interface{}) (interface{}, error) {
+	switch schemaType {
+	case "string":
+		return toolbox.AsString(value), nil
+	case "int64":
+		result, err := toolbox.ToInt(value)
+		return int64(result), err
+	case "float64":
+		return toolbox.ToFloat(value)
+	case "bool":
+		return toolbox.AsBoolean(value), nil
+	case "timestamp":
+		return asUpdateTime(value)
+	}
+	return nil, fmt.Errorf("unable to coerce %T to %v", value, schemaType)
+}
+
+//lookupValue returns record value for supplied column, dotted column is looked up in nested maps
+func lookupValue(record map[string]interface{}, column string) (interface{}, map[string]interface{}, string) {
+	if value, ok := record[column]; ok {
+		return value, record, column
+	}
+	index := strings.Index(column, ".")
+	if index == -1 {
+		return nil, nil, ""
+	}
+	nested, ok := record[column[:index]].(map[string]interface{})
+	if !ok {
+		return nil, nil, ""
+	}
+	return lookupValue(nested, column[index+1:])
+}
+
+//schema returns table schema registered with RegisterSchema, created with CreateTable, or declared with <table>.schema config parameter
+func (m *manager) schema(table string) *TableDescriptor {
+	if descriptor, _ := m.schemas.get(table); descriptor != nil {
+		return descriptor
+	}
+	if descriptor, _ := m.tables.get(table); descriptor != nil {
+		return descriptor
+	}
+	if specification := m.config.GetString(table+"."+schemaKey, ""); specification != "" {
+		descriptor, err := asTableDescriptor(table, specification)
+		if err == nil {
+			return descriptor
+		}
+	}
+	return nil
+}
+
+//validate checks record values against table schema column types, mismatched values are coerced with coerce mode, logged with warn mode, otherwise rejected, columns with transform functions are skipped
+func (m *manager) validate(table string, record map[string]interface{}, functions map[string]string) error {
+	mode := strings.ToLower(m.tableString(table, validationKey))
+	if mode == "" {
+		return nil
+	}
+	descriptor := m.schema(table)
+	if descriptor == nil {
+		return nil
+	}
+	for _, column := range descriptor.Columns {
+		schemaType, ok := schemaTypes[strings.ToLower(column.Type)]
+		if !ok || functions[column.Name] != "" {
+			continue
+		}
+		value, values, key := lookupValue(record, column.Name)
+		if value == nil || isSchemaType(schemaType, value) {
+			continue
+		}
+		switch mode {
+		case validationCoerce:
+			coerced, err := coerce(schemaType, value)
+			if err != nil {
+				return fmt.Errorf("invalid %v.%v value: %v, expected %v, %v", table, column.Name, value, column.Type, err)
+			}
+			values[key] = coerced
+		case validationWarn:
+			dsc.Logf("[%v]: invalid %v.%v value: %v, expected %v\n", m.config.dbName, table, column.Name, value, column.Type)
+		default:
+			return fmt.Errorf("invalid %v.%v value: %v (%T), expected %v", table, column.Name, value, value, column.Type)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package fsc
+
+import (
+	"cloud.google.com/go/firestore"
+	"fmt"
+	"golang.org/x/net/context"
+)
+
+//DuplicateKeyError represents inserted document that already exists
+type DuplicateKeyError struct {
+	Path string
+}
+
+//Error returns duplicate key error message
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("duplicate key: %v already exists", e.Path)
+}
+
+//IsDuplicateKey returns true if error or any of batch, bulk errors is a duplicate key error
+func IsDuplicateKey(err error) bool {
+	switch actual := err.(type) {
+	case *DuplicateKeyError:
+		return true
+	case *ChunkError:
+		return IsDuplicateKey(actual.Err)
+	case *BatchError:
+		for _, chunk := range actual.Chunks {
+			if IsDuplicateKey(chunk) {
+				return true
+			}
+		}
+	case *BulkError:
+		for _, failure := range actual.Failures {
+			if IsDuplicateKey(failure) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//write represents a single document write, record is created, merged or set if updates are empty and document is not deleted, generatedKey holds key column of generated document ID
+type write struct {
+	ref           *firestore.DocumentRef
+	record        map[string]interface{}
+	updates       []firestore.Update
+	delete        bool
+	create        bool
+	merge         bool
+	generatedKey  string
+	preconditions []firestore.Precondition
+}
+
+//setOptions returns set options
+func (w *write) setOptions() []firestore.SetOption {
+	if w.merge {
+		return []firestore.SetOption{firestore.MergeAll}
+	}
+	return nil
+}
+
+//apply applies write to the document
+func (w *write) apply(ctx context.Context) (err error) {
+	if w.delete {
+		_, err = w.ref.Delete(ctx, w.preconditions...)
+		return asWriteError(relativePath(w.ref.Path), err)
+	}
+	if len(w.updates) > 0 {
+		_, err = w.ref.Update(ctx, w.updates, w.preconditions...)
+		return asWriteError(relativePath(w.ref.Path), err)
+	}
+	if w.create {
+		_, err = w.ref.Create(ctx, w.record)
+		return asWriteError(relativePath(w.ref.Path), err)
+	}
+	_, err = w.ref.Set(ctx, w.record, w.setOptions()...)
+	return err
+}
+
+//addTo adds write to the batch
+func (w *write) addTo(batch *firestore.WriteBatch) {
+	if w.delete {
+		batch.Delete(w.ref, w.preconditions...)
+		return
+	}
+	if len(w.updates) > 0 {
+		batch.Update(w.ref, w.updates, w.preconditions...)
+		return
+	}
+	if w.create {
+		batch.Create(w.ref, w.record)
+		return
+	}
+	batch.Set(w.ref, w.record, w.setOptions()...)
+}
+
+//enqueue adds write to the bulk writer queue
+func (w *write) enqueue(bulkWriter *firestore.BulkWriter) (*firestore.BulkWriterJob, error) {
+	if w.delete {
+		return bulkWriter.Delete(w.ref, w.preconditions...)
+	}
+	if len(w.updates) > 0 {
+		return bulkWriter.Update(w.ref, w.updates, w.preconditions...)
+	}
+	if w.create {
+		return bulkWriter.Create(w.ref, w.record)
+	}
+	return bulkWriter.Set(w.ref, w.record, w.setOptions()...)
+}
+
+//applyWrites applies writes of a single record, writes are buffered in a transaction, bulk writer is used in bulk write mode
+func (m *manager) applyWrites(client *firestore.Client, ctx context.Context, writes []*write) error {
+	if transaction := asTransaction(ctx); transaction != nil {
+		transaction.add(writes...)
+		return nil
+	}
+	if m.config.bulkWrite {
+		_, err := m.writeBulk(client, ctx, [][]*write{writes})
+		return err
+	}
+	for _, write := range writes {
+		if err := write.apply(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}